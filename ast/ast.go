@@ -1,16 +1,71 @@
 package ast
 
+import (
+    "fmt"
+    "reflect"
+)
+
 // ASTNode represents a node in the Abstract Syntax Tree
 type ASTNode interface{}
 
+// Pos is a position in a source file. Every node records the position of the
+// token it starts at, so errors can point at file:line:column.
+type Pos struct {
+    File   string // Source file (empty when unknown)
+    Line   int    // 1-based line number (0 when unknown)
+    Column int    // 1-based column number
+}
+
+// IsValid reports whether the position refers to a real source location
+func (p Pos) IsValid() bool {
+    return p.Line > 0
+}
+
+// String formats the position as file:line:column (or line:column without a file)
+func (p Pos) String() string {
+    if !p.IsValid() {
+        return ""
+    }
+    if p.File == "" {
+        return fmt.Sprintf("%d:%d", p.Line, p.Column)
+    }
+    return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// PosOf returns the source position recorded in a node, or the zero Pos
+// if the node has no position (e.g., nil).
+func PosOf(node ASTNode) Pos {
+    if node == nil {
+        return Pos{}
+    }
+    value := reflect.ValueOf(node)
+    if value.Kind() == reflect.Ptr {
+        if value.IsNil() {
+            return Pos{}
+        }
+        value = value.Elem()
+    }
+    if value.Kind() != reflect.Struct {
+        return Pos{}
+    }
+    field := value.FieldByName("Pos")
+    if !field.IsValid() {
+        return Pos{}
+    }
+    pos, _ := field.Interface().(Pos)
+    return pos
+}
+
 // NumberNode represents a numeric literal
 type NumberNode struct {
     Value string
+    Pos   Pos // Source position
 }
 
 // IdentifierNode represents a variable or function name
 type IdentifierNode struct {
     Value string
+    Pos   Pos // Source position
 }
 
 // BinaryOpNode represents a binary operation (e.g., a + b)
@@ -18,34 +73,49 @@ type BinaryOpNode struct {
     Left  ASTNode
     Op    string
     Right ASTNode
+    Pos   Pos // Source position
+}
+
+// UnaryOpNode represents a prefix operation (e.g., -x or si kweli)
+type UnaryOpNode struct {
+    Op      string  // Operator ("-" or "si")
+    Operand ASTNode // The operand
+    Pos     Pos     // Source position
 }
 
 // ReturnNode represents a return statement
 type ReturnNode struct {
     Value ASTNode
-}
-
-// InputNode represents a user input operation
-type InputNode struct {
-    Prompt string // Optional prompt message
+    Pos   Pos // Source position
 }
 
 // FunctionCallNode represents a function call (e.g., andika(x, y))
 type FunctionCallNode struct {
-    Name string   // Function name
+    Name string    // Function name
     Args []ASTNode // Function arguments
+    Pos  Pos       // Source position
+}
+
+// CallNode represents calling the value of any expression (e.g., hifadhi["f"](2), tengeneza()(5))
+type CallNode struct {
+    Callee ASTNode   // Expression giving the function to call
+    Args   []ASTNode // Function arguments
+    Pos    Pos       // Source position
 }
 
 // VariableDeclarationNode represents a variable declaration (e.g., namba x = 10)
 type VariableDeclarationNode struct {
-    Name  string // Variable name
+    Name  string  // Variable name
+    Type  string  // Declared type (namba, boolean, kazi); empty for a plain assignment (x = 10)
     Value ASTNode // Variable value
+    Pos   Pos     // Source position
 }
 
 // Parameter represents a function parameter
 type Parameter struct {
     Name string // Parameter name
     Type string // Parameter type (namba, boolean)
+    Pos  Pos    // Source position
 }
 
 // FunctionNode represents a function definition (e.g., kazi kuu() { ... })
@@ -54,6 +124,7 @@ type FunctionNode struct {
     Parameters []Parameter // Function parameters
     ReturnType string      // Return type (optional)
     Body       []ASTNode   // Function body
+    Pos        Pos         // Source position
 }
 
 // IfNode represents a conditional statement (e.g., kama x > 5 { ... } sivyo { ... })
@@ -61,12 +132,14 @@ type IfNode struct {
     Condition ASTNode   // The condition to evaluate
     ThenBody  []ASTNode // Statements to execute if condition is true
     ElseBody  []ASTNode // Statements to execute if condition is false (optional)
+    Pos       Pos       // Source position
 }
 
 // WhileNode represents a while loop (e.g., wakati x < 10 { ... })
 type WhileNode struct {
     Condition ASTNode   // The condition to evaluate
     Body      []ASTNode // Statements to execute while condition is true
+    Pos       Pos       // Source position
 }
 
 // ForNode represents a for loop (e.g., kwa i = 0; i < 10; i = i + 1 { ... })
@@ -75,62 +148,142 @@ type ForNode struct {
     Condition ASTNode   // Loop condition (e.g., i < 10)
     Update    ASTNode   // Update statement (e.g., i = i + 1)
     Body      []ASTNode // Statements to execute in each iteration
+    Pos       Pos       // Source position
+}
+
+// ForEachNode represents a for-each loop (e.g., kwa kila x katika orodha { ... }
+// or kwa kila ufunguo, thamani katika kamusi { ... })
+type ForEachNode struct {
+    Key      string    // First of two loop variables: the index or key (optional)
+    Value    string    // Loop variable for the element, character or value; a key when alone over a kamusi
+    Iterable ASTNode   // The orodha, kamusi or maneno to go through
+    Body     []ASTNode // Statements to execute for each item
+    Pos      Pos       // Source position
+}
+
+// MatchNode represents a chagua statement (e.g., chagua x { wakati 1, 2 { ... } sivyo { ... } })
+type MatchNode struct {
+    Subject    ASTNode     // The value being matched
+    Cases      []MatchCase // wakati arms, tried in order
+    Default    []ASTNode   // Statements of the sivyo arm
+    HasDefault bool        // Whether there is a sivyo arm
+    Pos        Pos         // Source position
+}
+
+// MatchCase is one wakati arm of a chagua statement; it runs when any of its patterns matches
+type MatchCase struct {
+    Patterns []ASTNode // Alternative patterns (e.g., wakati 2, 3)
+    Body     []ASTNode // Statements to execute when a pattern matches
+    Pos      Pos       // Source position
+}
+
+// LiteralPattern matches a number, string or boolean equal to its value
+type LiteralPattern struct {
+    Value ASTNode // NumberNode, StringNode or BooleanNode
+    Pos   Pos     // Source position
+}
+
+// RangePattern matches a number between two bounds, inclusive (e.g., 10 hadi 20)
+type RangePattern struct {
+    Low  NumberNode // Smallest number matched
+    High NumberNode // Largest number matched
+    Pos  Pos        // Source position
+}
+
+// BindingPattern matches any value and stores it in a variable; _ matches without storing
+type BindingPattern struct {
+    Name string // Variable name, or _
+    Pos  Pos    // Source position
+}
+
+// ArrayPattern matches an orodha with exactly one element per pattern (e.g., [x, 0])
+type ArrayPattern struct {
+    Elements []ASTNode // Pattern for each element
+    Pos      Pos       // Source position
+}
+
+// DictPattern matches a kamusi that has each key, with a value matching its pattern
+// (e.g., {"jina": j}); other keys are ignored
+type DictPattern struct {
+    Keys   []string  // Keys that must be present
+    Values []ASTNode // Pattern for the value of each key
+    Pos    Pos       // Source position
+}
+
+// InstancePattern matches an object of a class or one of its subclasses, with
+// properties matching patterns (e.g., Mtu(jina, umri: 30)). A property named on
+// its own is stored in a variable of the same name.
+type InstancePattern struct {
+    ClassName  string    // Class the object must belong to
+    Properties []string  // Properties to match
+    Patterns   []ASTNode // Pattern for each property
+    Pos        Pos       // Source position
 }
 
 // BreakNode represents a break statement (vunja)
 type BreakNode struct {
-    // No additional fields needed
+    Pos Pos // Source position
 }
 
 // ContinueNode represents a continue statement (endelea)
 type ContinueNode struct {
-    // No additional fields needed
+    Pos Pos // Source position
 }
 
 // BooleanNode represents a boolean literal (kweli/uwongo)
 type BooleanNode struct {
     Value bool // true for kweli, false for uwongo
+    Pos   Pos  // Source position
 }
 
 // StringNode represents a string literal
 type StringNode struct {
     Value string // The string value without quotes
+    Pos   Pos    // Source position
 }
 
 // StringVariableDeclarationNode represents a string variable declaration (e.g., maneno x = "habari")
 type StringVariableDeclarationNode struct {
     Name  string  // Variable name
     Value ASTNode // Variable value
+    Pos   Pos     // Source position
 }
 
 // ArrayNode represents an array literal (e.g., [1, 2, 3])
 type ArrayNode struct {
     Elements []ASTNode // Array elements
+    Pos      Pos       // Source position
 }
 
 // ArrayDeclarationNode represents an array variable declaration (e.g., orodha namba = [1, 2, 3])
 type ArrayDeclarationNode struct {
-    Name     string  // Variable name
-    Type     string  // Element type (namba, maneno, etc.)
+    Name     string    // Variable name
+    Type     string    // Element type (namba, maneno, etc.)
     Elements []ASTNode // Initial elements
+    Value    ASTNode   // Initializer when it is not an array literal (e.g., a function call)
+    Pos      Pos       // Source position
 }
 
-// ArrayAccessNode represents array element access (e.g., arr[0])
+// ArrayAccessNode represents array element access (e.g., arr[0], matrix[i][j])
 type ArrayAccessNode struct {
-    Array ASTNode // The array being accessed
+    Array ASTNode // Expression giving the array or dictionary being accessed
     Index ASTNode // The index expression
+    Pos   Pos     // Source position
 }
 
-// ArrayAssignmentNode represents array element assignment (e.g., arr[0] = 5)
+// ArrayAssignmentNode represents array element assignment (e.g., arr[0] = 5, arr[0] += 5)
 type ArrayAssignmentNode struct {
     Array ASTNode // The array being modified
     Index ASTNode // The index expression
+    Op    string  // Operator combining the old value with Value (+ for +=); empty for =
     Value ASTNode // The new value
+    Pos   Pos     // Source position
 }
 
 // FileReadNode represents reading from a file (e.g., soma("file.txt"))
 type FileReadNode struct {
     Filename ASTNode // The filename to read from
+    Pos      Pos     // Source position
 }
 
 // FileWriteNode represents writing to a file (e.g., andika_faili("file.txt", "content"))
@@ -138,6 +291,7 @@ type FileWriteNode struct {
     Filename ASTNode // The filename to write to
     Content  ASTNode // The content to write
     Append   bool    // Whether to append or overwrite
+    Pos      Pos     // Source position
 }
 
 // TryNode represents a try-catch block (e.g., jaribu { ... } shika (hitilafu) { ... })
@@ -146,34 +300,40 @@ type TryNode struct {
     CatchVar    string    // Variable name for the caught error
     CatchBody   []ASTNode // Statements to execute if error occurs
     FinallyBody []ASTNode // Statements to execute regardless (optional)
+    Pos         Pos       // Source position
 }
 
 // ThrowNode represents throwing an error (e.g., tupa "Error message")
 type ThrowNode struct {
     Message ASTNode // The error message to throw
+    Pos     Pos     // Source position
 }
 
-// ImportNode represents an import statement (e.g., leta "math.swh")
+// ImportNode represents an import statement (e.g., leta "math.swh", leta "math.swh" kama
+// hesabu, or kutoka "math.swh" leta ongeza, toa)
 type ImportNode struct {
     ModulePath string   // Path to the module file
-    ImportName string   // Optional alias name
-    Items      []string // Specific items to import (for selective imports)
+    ImportName string   // Optional alias name (leta ... kama jina)
+    Items      []string // Specific items to import (for selective imports with kutoka)
+    Pos        Pos      // Source position
 }
 
-// ModuleNode represents a module definition
+// ModuleNode represents a file used as a module (e.g., moduli hesabu, with umma declarations)
 type ModuleNode struct {
-    Name      string      // Module name
-    Exports   []string    // List of exported function/variable names
-    Functions []ASTNode   // Functions in this module
+    Name      string    // Module name from moduli; empty to name it after its file
+    Exports   []string  // Names declared umma; when empty, everything is exported
+    Functions []ASTNode // Functions in this module
+    Pos       Pos       // Source position
 }
 
 // ClassNode represents a class definition (e.g., darasa Mtu { ... })
 type ClassNode struct {
-    Name       string         // Class name
-    Parent     string         // Parent class name (for inheritance)
-    Properties []PropertyNode // Class properties
-    Methods    []FunctionNode // Class methods
-    Constructor *FunctionNode // Constructor method (optional)
+    Name        string         // Class name
+    Parent      string         // Parent class name (for inheritance)
+    Properties  []PropertyNode // Class properties
+    Methods     []FunctionNode // Class methods
+    Constructor *FunctionNode  // Constructor method (optional)
+    Pos         Pos            // Source position
 }
 
 // PropertyNode represents a class property
@@ -181,37 +341,43 @@ type PropertyNode struct {
     Name  string  // Property name
     Type  string  // Property type (namba, maneno, boolean, etc.)
     Value ASTNode // Default value (optional)
+    Pos   Pos     // Source position
 }
 
 // NewInstanceNode represents creating a new instance (e.g., unda Mtu("Amina", 25))
 type NewInstanceNode struct {
     ClassName string    // Name of the class to instantiate
     Args      []ASTNode // Constructor arguments
+    Pos       Pos       // Source position
 }
 
-// MemberAccessNode represents accessing a member (e.g., mtu.jina)
+// MemberAccessNode represents accessing a member (e.g., mtu.jina, mtu.anwani.mji)
 type MemberAccessNode struct {
-    Object ASTNode // The object being accessed
+    Object ASTNode // Expression giving the object being accessed
     Member string  // The member name
+    Pos    Pos     // Source position
 }
 
-// MethodCallNode represents calling a method with dot notation (e.g., mtu.salamu())
+// MethodCallNode represents calling a method with dot notation (e.g., mtu.salamu(), watu[0].salamu())
 type MethodCallNode struct {
-    Object ASTNode   // The object whose method is being called
+    Object ASTNode   // Expression giving the object whose method is being called
     Method string    // The method name
     Args   []ASTNode // Method arguments
+    Pos    Pos       // Source position
 }
 
-// MemberAssignmentNode represents assigning to a member (e.g., mtu.jina = "Fatuma")
+// MemberAssignmentNode represents assigning to a member (e.g., mtu.jina = "Fatuma", hii.umri += 1)
 type MemberAssignmentNode struct {
     Object ASTNode // The object being modified
     Member string  // The member name
+    Op     string  // Operator combining the old value with Value (+ for +=); empty for =
     Value  ASTNode // The new value
+    Pos    Pos     // Source position
 }
 
 // ThisNode represents the 'hii' keyword (this/self)
 type ThisNode struct {
-    // No additional fields needed
+    Pos Pos // Source position
 }
 
 // ClassVariableDeclarationNode represents a class instance variable (e.g., Mtu mtu1 = unda Mtu())
@@ -219,12 +385,13 @@ type ClassVariableDeclarationNode struct {
     ClassName string  // Class name (type)
     VarName   string  // Variable name
     Value     ASTNode // Initialization value (NewInstanceNode)
+    Pos       Pos     // Source position
 }
 
-
 // DictionaryNode represents a dictionary/map literal (e.g., {"key": "value", "age": 25})
 type DictionaryNode struct {
 	Pairs []DictionaryPair // Key-value pairs
+	Pos   Pos              // Source position
 }
 
 // DictionaryPair represents a key-value pair in a dictionary
@@ -237,12 +404,14 @@ type DictionaryPair struct {
 type DictionaryDeclarationNode struct {
 	Name  string  // Variable name
 	Value ASTNode // Dictionary value
+	Pos   Pos     // Source position
 }
 
 // DictionaryAccessNode represents accessing a dictionary value (e.g., dict["key"])
 type DictionaryAccessNode struct {
 	Dictionary ASTNode // The dictionary being accessed
 	Key        ASTNode // The key expression
+	Pos        Pos     // Source position
 }
 
 // DictionaryAssignmentNode represents assigning to a dictionary (e.g., dict["key"] = value)
@@ -250,6 +419,7 @@ type DictionaryAssignmentNode struct {
 	Dictionary ASTNode // The dictionary being modified
 	Key        ASTNode // The key expression
 	Value      ASTNode // The new value
+	Pos        Pos     // Source position
 }
 
 // LambdaNode represents a lambda/anonymous function
@@ -257,4 +427,5 @@ type LambdaNode struct {
 	Parameters []Parameter // Lambda parameters
 	ReturnType string      // Return type (optional)
 	Body       []ASTNode   // Lambda body
+	Pos        Pos         // Source position
 }
@@ -0,0 +1,446 @@
+// Package checker compares the types written in a Kwenda program with the
+// values it stores, passes and returns, so mistakes such as namba x = "habari"
+// are reported before the program runs.
+package checker
+
+import (
+	"fmt"
+	"kwenda/ast"
+	"kwenda/interpreter"
+	"sort"
+)
+
+// Checker walks a parsed program and collects type mismatches
+type Checker struct {
+	functions map[string]ast.FunctionNode // Functions by name
+	classes   map[string]ast.ClassNode    // Classes by name
+	globals   *scope                      // Variables declared at the top level
+	errors    []Error
+}
+
+// scope holds the declared types of variables in one function body or block
+type scope struct {
+	types   map[string]string         // Declared types by variable name
+	lambdas map[string]ast.LambdaNode // Lambdas stored in variables, for checking calls
+	parent  *scope
+}
+
+// context describes the function or lambda whose body is being checked
+type context struct {
+	name       string // Function name used in messages
+	returnType string // Declared return type ("" if none)
+	class      string // Class of hii inside a method ("" outside classes)
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{types: make(map[string]string), lambdas: make(map[string]ast.LambdaNode), parent: parent}
+}
+
+// lookup finds the scope that declares name, searching outwards
+func (s *scope) lookup(name string) *scope {
+	for ; s != nil; s = s.parent {
+		if _, exists := s.types[name]; exists {
+			return s
+		}
+		if _, exists := s.lambdas[name]; exists {
+			return s
+		}
+	}
+	return nil
+}
+
+// Check reports the type mismatches in a program's top-level declarations, in source order
+func Check(program []ast.ASTNode) []Error {
+	c := &Checker{
+		functions: make(map[string]ast.FunctionNode),
+		classes:   make(map[string]ast.ClassNode),
+		globals:   newScope(nil),
+	}
+	// Every program has its command-line arguments in hoja
+	c.globals.types["hoja"] = "orodha maneno"
+	top := &context{}
+	c.declare(program)
+	for _, node := range program {
+		c.statement(node, c.globals, top)
+	}
+
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		a, b := c.errors[i].Pos, c.errors[j].Pos
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+	return c.errors
+}
+
+// declare records the functions and classes in a block so they can be used before
+// the line that defines them
+func (c *Checker) declare(block []ast.ASTNode) {
+	for _, node := range block {
+		switch n := node.(type) {
+		case ast.FunctionNode:
+			c.functions[n.Name] = n
+		case ast.ClassNode:
+			c.classes[n.Name] = n
+		}
+	}
+}
+
+// block checks a sequence of statements
+func (c *Checker) block(body []ast.ASTNode, s *scope, ctx *context) {
+	c.declare(body)
+	for _, node := range body {
+		c.statement(node, s, ctx)
+	}
+}
+
+// function checks a function, method or lambda body. Functions only see their
+// parameters and the globals; lambdas also see the scope they were written in.
+func (c *Checker) function(parameters []ast.Parameter, body []ast.ASTNode, parent *scope, ctx *context) {
+	s := newScope(parent)
+	for _, param := range parameters {
+		s.types[param.Name] = param.Type
+	}
+	c.block(body, s, ctx)
+}
+
+// statement checks one statement in scope s
+func (c *Checker) statement(node ast.ASTNode, s *scope, ctx *context) {
+	switch n := node.(type) {
+	case ast.VariableDeclarationNode:
+		valueType := c.expression(n.Value, s, ctx)
+		if n.Type != "" {
+			c.variable(n.Pos, n.Name, n.Type, valueType)
+			s.types[n.Name] = n.Type
+		} else if declared, exists := s.types[n.Name]; exists {
+			c.variable(n.Pos, n.Name, declared, valueType)
+		} else {
+			// Assignment always makes a variable in the current scope, hiding any outer one
+			s.types[n.Name] = ""
+		}
+		if lambda, ok := n.Value.(ast.LambdaNode); ok {
+			s.lambdas[n.Name] = lambda
+		}
+
+	case ast.StringVariableDeclarationNode:
+		c.variable(n.Pos, n.Name, "maneno", c.expression(n.Value, s, ctx))
+		s.types[n.Name] = "maneno"
+
+	case ast.DictionaryDeclarationNode:
+		c.variable(n.Pos, n.Name, "kamusi", c.expression(n.Value, s, ctx))
+		s.types[n.Name] = "kamusi"
+
+	case ast.ArrayDeclarationNode:
+		declared := "orodha"
+		if n.Type != "" {
+			declared += " " + n.Type
+		}
+		for _, element := range n.Elements {
+			c.mismatch(ast.PosOf(element), n.Type, c.expression(element, s, ctx),
+				fmt.Sprintf("Thamani ya kipengele cha orodha '%s'", n.Name), fmt.Sprintf("element of array '%s'", n.Name))
+		}
+		if n.Value != nil {
+			c.variable(n.Pos, n.Name, declared, c.expression(n.Value, s, ctx))
+		}
+		s.types[n.Name] = declared
+
+	case ast.ClassVariableDeclarationNode:
+		c.variable(n.Pos, n.VarName, n.ClassName, c.expression(n.Value, s, ctx))
+		s.types[n.VarName] = n.ClassName
+
+	case ast.MemberAssignmentNode:
+		objectType := c.expression(n.Object, s, ctx)
+		valueType := c.expression(n.Value, s, ctx)
+		if prop, class, found := c.property(objectType, n.Member); found {
+			if n.Op != "" {
+				valueType = binaryType(n.Op, prop.Type, valueType)
+			}
+			c.mismatch(n.Pos, prop.Type, valueType,
+				fmt.Sprintf("Sifa '%s' ya darasa '%s'", n.Member, class), fmt.Sprintf("property '%s' of class '%s'", n.Member, class))
+		}
+
+	case ast.ArrayAssignmentNode:
+		arrayType := c.expression(n.Array, s, ctx)
+		c.expression(n.Index, s, ctx)
+		valueType := c.expression(n.Value, s, ctx)
+		if isArray(arrayType) {
+			if n.Op != "" {
+				valueType = binaryType(n.Op, elementType(arrayType), valueType)
+			}
+			c.mismatch(n.Pos, elementType(arrayType), valueType, "Thamani ya kipengele cha orodha", "array element")
+		}
+
+	case ast.ReturnNode:
+		if n.Value == nil {
+			return
+		}
+		valueType := c.expression(n.Value, s, ctx)
+		c.mismatch(n.Pos, ctx.returnType, valueType,
+			fmt.Sprintf("Thamani inayorudishwa na '%s'", ctx.name), fmt.Sprintf("value returned by '%s'", ctx.name))
+
+	case ast.ThrowNode:
+		c.expression(n.Message, s, ctx)
+
+	case ast.IfNode:
+		c.expression(n.Condition, s, ctx)
+		c.block(n.ThenBody, s, ctx)
+		c.block(n.ElseBody, s, ctx)
+
+	case ast.WhileNode:
+		c.expression(n.Condition, s, ctx)
+		c.block(n.Body, s, ctx)
+
+	case ast.ForNode:
+		if n.Init != nil {
+			c.statement(n.Init, s, ctx)
+		}
+		if n.Condition != nil {
+			c.expression(n.Condition, s, ctx)
+		}
+		if n.Update != nil {
+			c.statement(n.Update, s, ctx)
+		}
+		c.block(n.Body, s, ctx)
+
+	case ast.ForEachNode:
+		keyType, valueType := itemTypes(c.expression(n.Iterable, s, ctx), n.Key != "")
+		if n.Key != "" {
+			s.types[n.Key] = keyType
+		}
+		s.types[n.Value] = valueType
+		c.block(n.Body, s, ctx)
+
+	case ast.MatchNode:
+		c.match(n, s, ctx)
+
+	case ast.TryNode:
+		c.block(n.TryBody, s, ctx)
+		// The caught error lives in its own scope
+		catch := newScope(s)
+		catch.types[n.CatchVar] = ""
+		c.block(n.CatchBody, catch, ctx)
+		c.block(n.FinallyBody, s, ctx)
+
+	case ast.FunctionNode:
+		c.function(n.Parameters, n.Body, c.globals, &context{name: n.Name, returnType: n.ReturnType, class: ctx.class})
+
+	case ast.ClassNode:
+		c.class(n)
+
+	default:
+		// Calls and other expressions used as statements
+		c.expression(node, s, ctx)
+	}
+}
+
+// variable checks a value stored in a variable declared with type declared
+func (c *Checker) variable(pos ast.Pos, name, declared, actual string) {
+	c.mismatch(pos, declared, actual, fmt.Sprintf("Thamani ya kigezo '%s'", name), fmt.Sprintf("value of variable '%s'", name))
+}
+
+// class checks property defaults, methods and the constructor of a class
+func (c *Checker) class(class ast.ClassNode) {
+	for _, prop := range class.Properties {
+		if prop.Value != nil {
+			c.mismatch(prop.Pos, prop.Type, c.expression(prop.Value, c.globals, &context{class: class.Name}),
+				fmt.Sprintf("Sifa '%s' ya darasa '%s'", prop.Name, class.Name), fmt.Sprintf("property '%s' of class '%s'", prop.Name, class.Name))
+		}
+	}
+	for _, method := range class.Methods {
+		c.function(method.Parameters, method.Body, c.globals,
+			&context{name: class.Name + "." + method.Name, returnType: method.ReturnType, class: class.Name})
+	}
+	if constructor := class.Constructor; constructor != nil {
+		c.function(constructor.Parameters, constructor.Body, c.globals,
+			&context{name: class.Name + ".unda", class: class.Name})
+	}
+}
+
+// property finds a property of a class or its parents
+func (c *Checker) property(className, name string) (ast.PropertyNode, string, bool) {
+	seen := make(map[string]bool)
+	for className != "" && !seen[className] {
+		seen[className] = true
+		class, exists := c.classes[className]
+		if !exists {
+			break
+		}
+		for _, prop := range class.Properties {
+			if prop.Name == name {
+				return prop, class.Name, true
+			}
+		}
+		className = class.Parent
+	}
+	return ast.PropertyNode{}, "", false
+}
+
+// method finds a method of a class or its parents
+func (c *Checker) method(className, name string) (ast.FunctionNode, string, bool) {
+	seen := make(map[string]bool)
+	for className != "" && !seen[className] {
+		seen[className] = true
+		class, exists := c.classes[className]
+		if !exists {
+			break
+		}
+		for _, method := range class.Methods {
+			if method.Name == name {
+				return method, class.Name, true
+			}
+		}
+		className = class.Parent
+	}
+	return ast.FunctionNode{}, "", false
+}
+
+// arguments checks the arguments of a call against the parameters of the function name
+func (c *Checker) arguments(pos ast.Pos, name string, parameters []ast.Parameter, args []ast.ASTNode, types []string) {
+	if len(args) != len(parameters) {
+		c.argumentCount(pos, name, len(parameters), len(args))
+	}
+	for i, param := range parameters {
+		if i < len(args) {
+			c.mismatch(ast.PosOf(args[i]), param.Type, types[i],
+				fmt.Sprintf("Hoja '%s' ya '%s'", param.Name, name), fmt.Sprintf("argument '%s' of '%s'", param.Name, name))
+		}
+	}
+}
+
+// expressions works out the types of a list of expressions
+func (c *Checker) expressions(nodes []ast.ASTNode, s *scope, ctx *context) []string {
+	types := make([]string, len(nodes))
+	for i, node := range nodes {
+		types[i] = c.expression(node, s, ctx)
+	}
+	return types
+}
+
+// expression checks an expression and returns its type, or "" if it cannot be known
+func (c *Checker) expression(node ast.ASTNode, s *scope, ctx *context) string {
+	switch n := node.(type) {
+	case ast.NumberNode:
+		return "namba"
+
+	case ast.StringNode:
+		return "maneno"
+
+	case ast.BooleanNode:
+		return "boolean"
+
+	case ast.ThisNode:
+		return ctx.class
+
+	case ast.IdentifierNode:
+		if owner := s.lookup(n.Value); owner != nil {
+			return owner.types[n.Value]
+		}
+		if _, exists := c.functions[n.Value]; exists {
+			// A named kazi used as a value, such as an argument to ramani
+			return "kazi"
+		}
+		return ""
+
+	case ast.BinaryOpNode:
+		left := c.expression(n.Left, s, ctx)
+		right := c.expression(n.Right, s, ctx)
+		return binaryType(n.Op, left, right)
+
+	case ast.UnaryOpNode:
+		c.expression(n.Operand, s, ctx)
+		switch n.Op {
+		case "-":
+			return "namba"
+		case "si":
+			return "boolean"
+		}
+		return ""
+
+	case ast.ArrayNode:
+		// An array literal whose elements share a type is an array of that type
+		common := ""
+		for i, elementType := range c.expressions(n.Elements, s, ctx) {
+			if i == 0 {
+				common = elementType
+			} else if elementType != common {
+				common = ""
+			}
+		}
+		if common == "" {
+			return "orodha"
+		}
+		return "orodha " + common
+
+	case ast.DictionaryNode:
+		for _, pair := range n.Pairs {
+			c.expression(pair.Key, s, ctx)
+			c.expression(pair.Value, s, ctx)
+		}
+		return "kamusi"
+
+	case ast.ArrayAccessNode:
+		arrayType := c.expression(n.Array, s, ctx)
+		c.expression(n.Index, s, ctx)
+		if isArray(arrayType) {
+			return elementType(arrayType)
+		}
+		return ""
+
+	case ast.MemberAccessNode:
+		if prop, _, found := c.property(c.expression(n.Object, s, ctx), n.Member); found {
+			return prop.Type
+		}
+		return ""
+
+	case ast.MethodCallNode:
+		objectType := c.expression(n.Object, s, ctx)
+		types := c.expressions(n.Args, s, ctx)
+		if method, class, found := c.method(objectType, n.Method); found {
+			c.arguments(n.Pos, class+"."+method.Name, method.Parameters, n.Args, types)
+			return method.ReturnType
+		}
+		return ""
+
+	case ast.NewInstanceNode:
+		types := c.expressions(n.Args, s, ctx)
+		class, exists := c.classes[n.ClassName]
+		if !exists {
+			return ""
+		}
+		if class.Constructor != nil {
+			c.arguments(n.Pos, class.Name+".unda", class.Constructor.Parameters, n.Args, types)
+		}
+		return class.Name
+
+	case ast.FunctionCallNode:
+		types := c.expressions(n.Args, s, ctx)
+		// The program's own functions hide built-ins with the same name
+		if owner := s.lookup(n.Name); owner != nil {
+			if lambda, ok := owner.lambdas[n.Name]; ok {
+				c.arguments(n.Pos, n.Name, lambda.Parameters, n.Args, types)
+				return lambda.ReturnType
+			}
+		}
+		if function, exists := c.functions[n.Name]; exists {
+			c.arguments(n.Pos, n.Name, function.Parameters, n.Args, types)
+			return function.ReturnType
+		}
+		if b, exists := interpreter.LookupBuiltin(n.Name, len(n.Args)); exists {
+			for i, arg := range n.Args {
+				c.mismatch(ast.PosOf(arg), b.ArgType(i), types[i],
+					fmt.Sprintf("Hoja ya %d ya '%s'", i+1, n.Name), fmt.Sprintf("argument %d of '%s'", i+1, n.Name))
+			}
+			return c.builtinType(b, n.Args, types, s)
+		}
+		return ""
+
+	case ast.CallNode:
+		calleeType := c.expression(n.Callee, s, ctx)
+		c.expressions(n.Args, s, ctx)
+		c.mismatch(ast.PosOf(n.Callee), "kazi", calleeType, "Thamani inayoitwa", "the value being called")
+		return c.returnType(n.Callee, s)
+
+	case ast.LambdaNode:
+		c.function(n.Parameters, n.Body, s, &context{name: "lambda", returnType: n.ReturnType, class: ctx.class})
+		return "kazi"
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package checker
+
+import (
+	"fmt"
+	"kwenda/ast"
+)
+
+// Error is a type mismatch found before the program runs, with a bilingual message
+type Error struct {
+	Pos     ast.Pos // Where the mismatch was found
+	Message string  // Swahili description
+	English string  // English description
+	Warning bool    // A likely mistake that does not stop the program from running
+}
+
+// Error formats the diagnostic as file:line:column: ujumbe (message), marking
+// warnings with onyo
+func (e Error) Error() string {
+	message := e.Message
+	if e.English != "" {
+		message += " (" + e.English + ")"
+	}
+	if e.Warning {
+		message = "onyo (warning): " + message
+	}
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + message
+	}
+	return message
+}
+
+// mismatch records a value of type actual used where expected is required
+func (c *Checker) mismatch(pos ast.Pos, expected, actual string, swWhat, enWhat string) {
+	if c.compatible(expected, actual) {
+		return
+	}
+	c.errors = append(c.errors, Error{
+		Pos:     pos,
+		Message: fmt.Sprintf("%s inapaswa kuwa %s lakini ni %s", swWhat, expected, actual),
+		English: fmt.Sprintf("%s should be %s but is %s", enWhat, expected, actual),
+	})
+}
+
+// argumentCount records a call with the wrong number of arguments
+func (c *Checker) argumentCount(pos ast.Pos, name string, want, got int) {
+	c.errors = append(c.errors, Error{
+		Pos:     pos,
+		Message: fmt.Sprintf("'%s' inahitaji hoja %d lakini imepewa %d", name, want, got),
+		English: fmt.Sprintf("'%s' takes %d arguments but was given %d", name, want, got),
+	})
+}
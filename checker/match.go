@@ -0,0 +1,94 @@
+package checker
+
+import "kwenda/ast"
+
+// match checks a chagua statement: the variables each pattern binds get the type
+// of the value they match, and a chagua that may match nothing is a warning
+func (c *Checker) match(n ast.MatchNode, s *scope, ctx *context) {
+	subjectType := c.expression(n.Subject, s, ctx)
+	for _, arm := range n.Cases {
+		for _, pattern := range arm.Patterns {
+			c.pattern(pattern, subjectType, s)
+		}
+		c.block(arm.Body, s, ctx)
+	}
+	c.block(n.Default, s, ctx)
+
+	if !n.HasDefault && !c.exhaustive(n.Cases, subjectType) {
+		c.errors = append(c.errors, Error{
+			Pos:     n.Pos,
+			Message: "chagua haina tawi la 'sivyo', kwa hivyo thamani isiyolingana na tawi lolote haitafanya kitu",
+			English: "chagua has no 'sivyo' arm, so a value that matches no arm does nothing",
+			Warning: true,
+		})
+	}
+}
+
+// pattern declares the variables a pattern binds when it matches a value of type t
+func (c *Checker) pattern(pattern ast.ASTNode, t string, s *scope) {
+	switch p := pattern.(type) {
+	case ast.BindingPattern:
+		if p.Name != "_" {
+			s.types[p.Name] = t
+		}
+	case ast.ArrayPattern:
+		element := ""
+		if isArray(t) {
+			element = elementType(t)
+		}
+		for _, e := range p.Elements {
+			c.pattern(e, element, s)
+		}
+	case ast.DictPattern:
+		for _, value := range p.Values {
+			c.pattern(value, "", s)
+		}
+	case ast.InstancePattern:
+		for i, name := range p.Properties {
+			prop, _, _ := c.property(p.ClassName, name)
+			c.pattern(p.Patterns[i], prop.Type, s)
+		}
+	}
+}
+
+// exhaustive reports whether the arms of a chagua match every value of type t:
+// an arm with a pattern that cannot fail matches anything, and kweli and uwongo
+// together cover a boolean
+func (c *Checker) exhaustive(cases []ast.MatchCase, t string) bool {
+	booleans := make(map[bool]bool)
+	for _, arm := range cases {
+		for _, pattern := range arm.Patterns {
+			if c.irrefutable(pattern, t) {
+				return true
+			}
+			if p, ok := pattern.(ast.LiteralPattern); ok {
+				if b, ok := p.Value.(ast.BooleanNode); ok {
+					booleans[b.Value] = true
+				}
+			}
+		}
+	}
+	return t == "boolean" && booleans[true] && booleans[false]
+}
+
+// irrefutable reports whether a pattern matches every value of type t: a bare
+// variable or _, or an object pattern for t's class (or one it inherits from)
+// whose property patterns cannot fail either, such as Mtu(jina) for a Mtu
+func (c *Checker) irrefutable(pattern ast.ASTNode, t string) bool {
+	switch p := pattern.(type) {
+	case ast.BindingPattern:
+		return true
+	case ast.InstancePattern:
+		if t == "" || !c.isSubclass(t, p.ClassName) {
+			return false
+		}
+		for i, name := range p.Properties {
+			prop, _, exists := c.property(p.ClassName, name)
+			if !exists || !c.irrefutable(p.Patterns[i], prop.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package checker
+
+import (
+	"kwenda/ast"
+	"kwenda/interpreter"
+	"strings"
+)
+
+// Types are the names used in declarations: namba, maneno, boolean, kamusi, kazi,
+// orodha or "orodha <element>", and class names. The empty string is a type the
+// checker cannot work out, which is compatible with everything.
+
+// elementType returns the element type of an array type, or "" if it is not known
+func elementType(t string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(t, "orodha"), " ")
+}
+
+// isArray reports whether t is an array type
+func isArray(t string) bool {
+	return t == "orodha" || strings.HasPrefix(t, "orodha ")
+}
+
+// itemTypes returns the types of the key and item a for-each loop over a value of
+// type t gives; with a single loop variable a kamusi gives its keys
+func itemTypes(t string, pairs bool) (key, item string) {
+	switch {
+	case isArray(t):
+		return "namba", elementType(t)
+	case t == "maneno":
+		return "namba", "maneno"
+	case t == "kamusi" && pairs:
+		return "maneno", ""
+	case t == "kamusi":
+		return "maneno", "maneno"
+	}
+	return "", ""
+}
+
+// isBasic reports whether t is one of the language's own type names
+func isBasic(t string) bool {
+	switch t {
+	case "namba", "maneno", "boolean", "kamusi", "kazi":
+		return true
+	}
+	return isArray(t)
+}
+
+// compatible reports whether a value of type actual may be used where expected is
+// required. Unknown types match anything, and an object may stand in for any of
+// its parent classes.
+func (c *Checker) compatible(expected, actual string) bool {
+	if expected == "" || actual == "" || expected == actual {
+		return true
+	}
+	if isArray(expected) && isArray(actual) {
+		return c.compatible(elementType(expected), elementType(actual))
+	}
+	if _, isObject := c.classes[actual]; isObject && expected == "kamusi" {
+		// Objects started out as dictionaries, and kamusi variables may still hold them
+		return true
+	}
+	if !isBasic(expected) {
+		if _, known := c.classes[expected]; !known {
+			// Not a type the checker knows about, so it cannot be checked
+			return true
+		}
+	}
+	return c.isSubclass(actual, expected)
+}
+
+// isSubclass reports whether class child is parent or inherits from it
+func (c *Checker) isSubclass(child, parent string) bool {
+	seen := make(map[string]bool)
+	for child != "" && !seen[child] {
+		if child == parent {
+			return true
+		}
+		seen[child] = true
+		class, exists := c.classes[child]
+		if !exists {
+			return false
+		}
+		child = class.Parent
+	}
+	return false
+}
+
+// binaryType returns the type of left op right
+func binaryType(op, left, right string) string {
+	switch op {
+	case "+":
+		// Adding anything to text joins them as text
+		if left == "maneno" || right == "maneno" {
+			return "maneno"
+		}
+		if left == "namba" && right == "namba" {
+			return "namba"
+		}
+		return ""
+	case "-", "*", "/", "%", "**":
+		return "namba"
+	case "==", "!=", "<", "<=", ">", ">=", "na", "au":
+		return "boolean"
+	}
+	return ""
+}
+
+// builtinType returns the type of a call to a built-in whose result depends on
+// its arguments, and otherwise the built-in's declared result type
+func (c *Checker) builtinType(b *interpreter.Builtin, args []ast.ASTNode, types []string, s *scope) string {
+	switch b.Name {
+	case "pata":
+		// pata returns an element of the array it is given
+		if isArray(types[0]) {
+			return elementType(types[0])
+		}
+	case "chuja", "panga", "geuza":
+		// These keep the elements of the array they are given
+		if isArray(types[0]) {
+			return types[0]
+		}
+	case "tafuta_kwanza":
+		if isArray(types[0]) {
+			return elementType(types[0])
+		}
+	case "ramani":
+		if result := c.returnType(args[1], s); result != "" {
+			return "orodha " + result
+		}
+	}
+	return b.ReturnType
+}
+
+// returnType returns the declared result type of a function expression: a
+// lambda, a variable holding one, or a named kazi
+func (c *Checker) returnType(node ast.ASTNode, s *scope) string {
+	switch n := node.(type) {
+	case ast.LambdaNode:
+		return n.ReturnType
+	case ast.IdentifierNode:
+		if owner := s.lookup(n.Value); owner != nil {
+			return owner.lambdas[n.Value].ReturnType
+		}
+		return c.functions[n.Value].ReturnType
+	}
+	return ""
+}
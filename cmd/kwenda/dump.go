@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"kwenda/ast"
+	"kwenda/lexer"
+	"kwenda/parser"
+	"reflect"
+	"strings"
+)
+
+// printTokens lists the tokens of a program one per line, with their positions (--tokens)
+func printTokens(w io.Writer, tokens []lexer.Token) {
+	fmt.Fprintln(w, "Tokens:")
+	for _, tok := range tokens {
+		fmt.Fprintf(w, "  %d:%d\t%s\t%q\n", tok.Line, tok.Column, tok.Type, tok.Value)
+	}
+}
+
+// printAST shows the syntax tree of a program as an indented outline (--ast)
+func printAST(w io.Writer, program parser.ProgramNode) {
+	fmt.Fprintln(w, "AST:")
+	for _, imp := range program.Imports {
+		dumpValue(w, reflect.ValueOf(imp), 1)
+	}
+	for _, node := range program.Functions {
+		dumpValue(w, reflect.ValueOf(node), 1)
+	}
+}
+
+var posType = reflect.TypeOf(ast.Pos{})
+
+// dumpValue writes a node as its type and position followed by its fields, one
+// level of indentation deeper. Empty fields are left out.
+func dumpValue(w io.Writer, v reflect.Value, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			fmt.Fprintf(w, "%s<nil>\n", indent)
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		fmt.Fprintf(w, "%s%v\n", indent, v.Interface())
+		return
+	}
+
+	header := v.Type().Name()
+	if pos := v.FieldByName("Pos"); pos.IsValid() && pos.Type() == posType {
+		if p := pos.Interface().(ast.Pos); p.IsValid() {
+			header += fmt.Sprintf(" @%d:%d", p.Line, p.Column)
+		}
+	}
+	fmt.Fprintf(w, "%s%s\n", indent, header)
+
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		if !field.IsExported() || field.Type == posType || value.IsZero() {
+			continue
+		}
+		switch value.Kind() {
+		case reflect.String, reflect.Bool, reflect.Int:
+			fmt.Fprintf(w, "%s  %s: %#v\n", indent, field.Name, value.Interface())
+		case reflect.Slice:
+			if value.Len() == 0 {
+				continue
+			}
+			if value.Type().Elem().Kind() == reflect.String {
+				fmt.Fprintf(w, "%s  %s: %q\n", indent, field.Name, value.Interface())
+				continue
+			}
+			fmt.Fprintf(w, "%s  %s:\n", indent, field.Name)
+			for j := 0; j < value.Len(); j++ {
+				dumpValue(w, value.Index(j), depth+2)
+			}
+		default:
+			fmt.Fprintf(w, "%s  %s:\n", indent, field.Name)
+			dumpValue(w, value, depth+2)
+		}
+	}
+}
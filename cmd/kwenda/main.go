@@ -0,0 +1,296 @@
+package main
+
+import (
+    "fmt"
+    "kwenda"
+    "kwenda/checker"
+    "kwenda/lexer"
+    "kwenda/parser"
+    "kwenda/interpreter"
+    "os"
+)
+
+// exitWithSyntaxErrors reports parse errors on stderr and stops the program
+func exitWithSyntaxErrors(errors []parser.Error) {
+    fmt.Fprintln(os.Stderr, &kwenda.SyntaxError{Errors: errors})
+    os.Exit(1)
+}
+
+// checkFile reports the syntax and type errors in a program without running it
+func checkFile(filename string, source string) {
+    program := parser.ParseProgram(lexer.LexFile(filename, source))
+    if len(program.Errors) > 0 {
+        exitWithSyntaxErrors(program.Errors)
+    }
+    
+    // Warnings are shown, but only errors make the check fail
+    var typeErrors, warnings []checker.Error
+    for _, err := range checker.Check(program.Functions) {
+        if err.Warning {
+            warnings = append(warnings, err)
+        } else {
+            typeErrors = append(typeErrors, err)
+        }
+    }
+    if len(warnings) > 0 {
+        fmt.Fprintf(os.Stderr, "Maonyo (warnings): %d\n", len(warnings))
+        for _, err := range warnings {
+            fmt.Fprintln(os.Stderr, "  "+err.Error())
+        }
+    }
+    if len(typeErrors) > 0 {
+        fmt.Fprintf(os.Stderr, "Hitilafu za aina (type errors): %d\n", len(typeErrors))
+        for _, err := range typeErrors {
+            fmt.Fprintln(os.Stderr, "  "+err.Error())
+        }
+        os.Exit(1)
+    }
+    fmt.Printf("%s: hakuna hitilafu za aina (no type errors)\n", filename)
+}
+
+// printBuiltins lists the built-in functions with their signatures and descriptions
+func printBuiltins() {
+    fmt.Println("Kazi za ndani (built-in functions):")
+    for _, b := range interpreter.Builtins() {
+        fmt.Printf("  %s\n      %s (%s)\n", b.Signature(), b.Doc, b.DocEnglish)
+    }
+}
+
+// printStdlib lists the standard library modules and what each one exports
+func printStdlib() {
+    fmt.Println("Maktaba ya kawaida (standard library):")
+    for _, module := range kwenda.Stdlib() {
+        fmt.Printf("\n  leta \"%s\"    (%s)\n", module.Name, module.File)
+        for _, export := range module.Exports {
+            fmt.Printf("      %s\n", export)
+        }
+    }
+}
+
+func printHelp() {
+    help := `
+╔═══════════════════════════════════════════════════════════════════════════╗
+║                    KWENDA - Swahili Programming Language                  ║
+║                    "Move Forward with Technology"                         ║
+╚═══════════════════════════════════════════════════════════════════════════╝
+
+USAGE:
+    kwenda <filename.swh> [args...]    Run a Kwenda program; the arguments after
+                                       the file are given to it in hoja
+    kwenda                             Start the interactive REPL
+    kwenda repl                        Start the interactive REPL
+    kwenda --vm <filename.swh>         Run a program on the bytecode VM
+    kwenda --legacy <filename.swh>     Treat undefined names as text and unknown
+                                       functions as tupu, as early versions did
+    kwenda --tokens <filename.swh>     Show the program's tokens before running it
+    kwenda --ast <filename.swh>        Show the program's syntax tree before running it
+    kwenda check <filename.swh>        Check declared types without running
+    kwenda builtins                    List the built-in functions
+    kwenda stdlib                      List the standard library modules
+    kwenda --help                      Show this help message
+    kwenda --version                   Show version information
+
+DESCRIPTION:
+    Kwenda is a fully-featured programming language with native Swahili syntax.
+    It's designed to make programming accessible to Swahili speakers while
+    providing modern programming capabilities.
+
+EXIT STATUS:
+    0 when the program finishes, or the whole number kuu returns (rudisha 3
+    exits with 3); 1 after a syntax error or an unhandled error; 2 for wrong usage.
+
+EXAMPLES:
+    kwenda hello.swh                   Run hello.swh program
+    kwenda examples/demo.swh           Run demo from examples folder
+
+BASIC SYNTAX:
+    kazi kuu() {                       # Main function (entry point)
+        andika("Habari!")              # Print to console
+    }
+
+KEYWORDS:
+    kazi        - Function declaration
+    kuu         - Main function name
+    andika      - Print/output
+    rudisha     - Return
+    namba       - Number type
+    maneno      - String type
+    boolean     - Boolean type
+    kamusi      - Dictionary type
+    orodha      - Array/list type
+    kama        - If statement
+    sivyo       - Else statement
+    wakati      - While loop
+    kwa         - For loop
+    darasa      - Class declaration
+    unda        - Create/instantiate
+    hii         - This/self reference
+    lambda      - Anonymous function
+    leta        - Import module
+    kutoka      - Import chosen names from a module
+    umma        - Export from a module
+
+FEATURES:
+    ✓ Variables and data types (numbers, strings, booleans)
+    ✓ Functions with parameters and return values
+    ✓ Control flow (if/else, while, for loops)
+    ✓ Arrays and dictionaries
+    ✓ Object-oriented programming (classes, inheritance)
+    ✓ Lambda/anonymous functions
+    ✓ Closures
+    ✓ Module system
+    ✓ Error handling (try/catch)
+    ✓ Standard library functions
+
+DOCUMENTATION:
+    README.md                          Full documentation
+    examples/                          Example programs
+    
+WEBSITE:
+    https://github.com/limo39/kwenda
+
+VERSION:
+    Kwenda v1.0.0 - Built with Go
+
+For more information, visit the documentation or run example programs.
+`
+    fmt.Println(help)
+}
+
+func printVersion() {
+    version := `
+Kwenda Programming Language
+Version: 1.0.0
+Built with: Go 1.23.3
+License: MIT
+
+Kwenda - "Move Forward" in Swahili
+A bridge to technology for everyone.
+`
+    fmt.Println(version)
+}
+
+func main() {
+    // Parse command line flags; the first other argument is the program to run,
+    // and everything after it is passed to the program as hoja.
+    // "kwenda check <file>" only checks the program for errors, and without a
+    // program (or with "kwenda repl") code is read interactively.
+    useVM := false
+    legacy := false
+    showTokens := false
+    showAST := false
+    checkOnly := false
+    filename := ""
+    var programArgs []string
+    for i, arg := range os.Args[1:] {
+        if filename != "" {
+            programArgs = os.Args[i+1:]
+            break
+        }
+        switch {
+        case i == 0 && arg == "check":
+            checkOnly = true
+        case i == 0 && arg == "repl":
+            // Same as running kwenda without a file
+        case i == 0 && arg == "builtins":
+            printBuiltins()
+            return
+        case i == 0 && arg == "stdlib":
+            printStdlib()
+            return
+        case arg == "--help" || arg == "-h":
+            printHelp()
+            return
+        case arg == "--version" || arg == "-v":
+            printVersion()
+            return
+        case arg == "--vm":
+            useVM = true
+        case arg == "--legacy":
+            legacy = true
+        case arg == "--tokens":
+            showTokens = true
+        case arg == "--ast":
+            showAST = true
+        default:
+            filename = arg
+        }
+    }
+    
+    // Check for command line arguments
+    if filename == "" && checkOnly {
+        fmt.Fprintln(os.Stderr, "Usage: kwenda check <filename.swh>")
+        fmt.Fprintln(os.Stderr, "Try 'kwenda --help' for more information.")
+        os.Exit(2)
+    }
+    rt := kwenda.NewRuntime()
+    if useVM {
+        rt.UseVM()
+    }
+    rt.SetLegacy(legacy)
+    rt.SetArgs(programArgs)
+    if filename == "" {
+        runREPL(rt)
+        return
+    }
+    
+    // Read the source code from a file
+    input, err := os.ReadFile(filename)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Haiwezi kusoma faili (cannot read file): %v\n", err)
+        os.Exit(1)
+    }
+    
+    if checkOnly {
+        checkFile(filename, string(input))
+        return
+    }
+    
+    // Lexical analysis and parsing, showing the tokens and tree when asked to
+    tokens := lexer.LexFile(filename, string(input))
+    if showTokens {
+        printTokens(os.Stdout, tokens)
+    }
+    program := parser.ParseProgram(tokens)
+    if showAST {
+        printAST(os.Stdout, program)
+    }
+
+    // Refuse to run a program with syntax errors
+    if len(program.Errors) > 0 {
+        exitWithSyntaxErrors(program.Errors)
+    }
+
+    // Interpretation, on the tree-walker or on bytecode with --vm
+    result, returned, err := rt.RunProgram(program)
+    os.Exit(exitCode(result, returned, err))
+}
+
+// exitCode reports an unhandled error and returns the status the process exits
+// with: 1 after an error, the whole number kuu gave to rudisha, else 0. Only
+// rudisha sets the status, so the value of kuu's last statement is ignored.
+func exitCode(result interpreter.Value, returned bool, err error) int {
+    if err != nil {
+        // Syntax errors in imported modules are reported like the program's own
+        if syntaxErr, ok := err.(*kwenda.SyntaxError); ok {
+            fmt.Fprintln(os.Stderr, syntaxErr)
+        } else {
+            interpreter.ReportError(os.Stderr, err)
+        }
+        return 1
+    }
+    if !returned {
+        return 0
+    }
+    if number, ok := result.(interpreter.NumberValue); ok && !number.IsFloat {
+        if number.Int < 0 || number.Int > 255 {
+            interpreter.ReportError(os.Stderr, interpreter.ErrorValue{
+                Message: fmt.Sprintf("kuu ilirudisha %d, lakini hali ya kutoka lazima iwe kati ya 0 na 255", number.Int),
+                Context: fmt.Sprintf("kuu returned %d, but an exit status must be between 0 and 255", number.Int),
+            })
+            return 1
+        }
+        return number.Int
+    }
+    return 0
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"kwenda"
+	"kwenda/interpreter"
+	"kwenda/lexer"
+	"os"
+	"sort"
+	"strings"
+)
+
+// replHelp describes the REPL commands
+const replHelp = `Amri (commands):
+  :msaada    Onyesha msaada huu (show this help)
+  :vigezo    Orodhesha vigezo, kazi na madarasa (list variables, functions and classes)
+  :futa      Futa kila kitu na uanze upya (forget everything and start again)
+  :toka      Toka kwenye REPL (quit; Ctrl+D also works)
+
+Andika msimbo wowote wa Kwenda; thamani ya usemi inaonyeshwa.
+Type any Kwenda code; the value of an expression is shown.
+Blocks continue on the next line until every '{' is closed.
+`
+
+// runREPL reads code from the terminal and runs it in rt, so variables,
+// functions, classes and imported modules stay available between inputs
+func runREPL(rt *kwenda.Runtime) {
+	in := bufio.NewReader(os.Stdin)
+	// ingiza reads from the same buffer as the REPL, so no input is lost between them
+	rt.SetStdin(in)
+
+	fmt.Println("Kwenda 1.0.0 REPL - andika :msaada kwa msaada (type :msaada for help)")
+	for {
+		source, ok := readEntry(in)
+		if !ok {
+			fmt.Println()
+			return
+		}
+
+		switch strings.TrimSpace(source) {
+		case "":
+			continue
+		case ":msaada":
+			fmt.Print(replHelp)
+			continue
+		case ":vigezo":
+			printVariables(rt.Environment())
+			continue
+		case ":futa":
+			rt.Reset()
+			fmt.Println("Kila kitu kimefutwa (everything has been reset)")
+			continue
+		case ":toka":
+			return
+		}
+		if strings.HasPrefix(strings.TrimSpace(source), ":") {
+			fmt.Printf("Amri '%s' haijulikani; jaribu :msaada (unknown command; try :msaada)\n", strings.TrimSpace(source))
+			continue
+		}
+
+		result, err := rt.Eval(source)
+		if err != nil {
+			if _, isSyntax := err.(*kwenda.SyntaxError); isSyntax {
+				fmt.Fprintln(os.Stderr, err)
+			} else {
+				interpreter.ReportError(os.Stderr, err)
+			}
+			continue
+		}
+		if _, isNil := result.(interpreter.NilValue); !isNil {
+			fmt.Println(result.String())
+		}
+	}
+}
+
+// readEntry reads one complete entry, continuing onto further lines while a
+// brace, bracket or parenthesis is still open. It reports false at the end of input.
+func readEntry(in *bufio.Reader) (string, bool) {
+	var entry strings.Builder
+	prompt := ">>> "
+	for {
+		fmt.Print(prompt)
+		line, err := in.ReadString('\n')
+		entry.WriteString(line)
+		if err != nil {
+			// Run what was typed before Ctrl+D, then stop
+			if err == io.EOF && strings.TrimSpace(entry.String()) != "" {
+				return entry.String(), true
+			}
+			return "", false
+		}
+		if openBrackets(entry.String()) <= 0 {
+			return entry.String(), true
+		}
+		prompt = "... "
+	}
+}
+
+// openBrackets counts the brackets that are opened but not yet closed in source,
+// ignoring any inside strings and comments
+func openBrackets(source string) int {
+	depth := 0
+	for _, tok := range lexer.Lex(source) {
+		if tok.Type != lexer.TokenPunctuation {
+			continue
+		}
+		switch tok.Value {
+		case "{", "(", "[":
+			depth++
+		case "}", ")", "]":
+			depth--
+		}
+	}
+	return depth
+}
+
+// printVariables lists the global variables, functions, classes and modules
+func printVariables(env *interpreter.Environment) {
+	if len(env.Variables)+len(env.Functions)+len(env.Classes)+len(env.Modules) == 0 {
+		fmt.Println("Hakuna vigezo bado (nothing defined yet)")
+		return
+	}
+	for _, name := range sortedKeys(env.Variables) {
+		value := env.Variables[name]
+		fmt.Printf("  %s %s = %s\n", value.Type(), name, value)
+	}
+	for _, name := range sortedKeys(env.Functions) {
+		var params []string
+		for _, param := range env.Functions[name].Parameters {
+			params = append(params, strings.TrimSpace(param.Type+" "+param.Name))
+		}
+		fmt.Printf("  kazi %s(%s)\n", name, strings.Join(params, ", "))
+	}
+	for _, name := range sortedKeys(env.Classes) {
+		fmt.Printf("  darasa %s\n", name)
+	}
+	for _, name := range sortedKeys(env.Modules) {
+		fmt.Printf("  moduli %s\n", name)
+	}
+}
+
+// sortedKeys returns the keys of a map in alphabetical order
+func sortedKeys[V any](entries map[string]V) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
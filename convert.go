@@ -0,0 +1,135 @@
+package kwenda
+
+import (
+	"fmt"
+	"kwenda/interpreter"
+	"reflect"
+	"sort"
+)
+
+// ToValue converts a Go value to a Kwenda value. Numbers, strings, booleans and
+// nil map to namba, maneno, boolean and tupu; slices and arrays become orodha;
+// maps with string keys become kamusi (in key order); errors become hitilafu; and
+// a func(...any) (any, error) becomes a function scripts can call. Kwenda values
+// are returned unchanged.
+func ToValue(value any) (interpreter.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return interpreter.Nil, nil
+	case interpreter.Value:
+		return v, nil
+	case bool:
+		return interpreter.BoolValue(v), nil
+	case string:
+		return interpreter.StringValue(v), nil
+	case int:
+		return interpreter.Int(v), nil
+	case float64:
+		return interpreter.Float(v), nil
+	case error:
+		return interpreter.ErrorValue{Message: v.Error()}, nil
+	case func(args ...any) (any, error):
+		return hostFunction("", v), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return interpreter.Int(int(rv.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return interpreter.Int(int(rv.Uint())), nil
+	case reflect.Float32:
+		return interpreter.Float(rv.Float()), nil
+	case reflect.Slice, reflect.Array:
+		elements := make([]interpreter.Value, rv.Len())
+		for i := range elements {
+			element, err := ToValue(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return interpreter.NewArray(elements), nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		dict := interpreter.NewDict()
+		for _, key := range keys {
+			entry, err := ToValue(rv.MapIndex(key).Interface())
+			if err != nil {
+				return nil, err
+			}
+			dict.Set(key.String(), entry)
+		}
+		return dict, nil
+	}
+	return nil, fmt.Errorf("kwenda: cannot convert %T to a Kwenda value", value)
+}
+
+// FromValue converts a Kwenda value to a Go value: int or float64, string, bool,
+// nil, []any for orodha, and map[string]any for kamusi and objects. Errors are
+// returned as interpreter.ErrorValue, which implements error; functions and
+// classes are returned as they are.
+func FromValue(value interpreter.Value) any {
+	switch v := value.(type) {
+	case nil, interpreter.NilValue:
+		return nil
+	case interpreter.NumberValue:
+		if v.IsFloat {
+			return v.Float
+		}
+		return v.Int
+	case interpreter.StringValue:
+		return string(v)
+	case interpreter.BoolValue:
+		return bool(v)
+	case *interpreter.ArrayValue:
+		elements := make([]any, len(v.Elements))
+		for i, element := range v.Elements {
+			elements[i] = FromValue(element)
+		}
+		return elements
+	case *interpreter.DictValue:
+		return dictToMap(v)
+	case *interpreter.InstanceValue:
+		return dictToMap(v.Fields)
+	}
+	return value
+}
+
+// dictToMap converts the entries of a dictionary
+func dictToMap(dict *interpreter.DictValue) map[string]any {
+	entries := make(map[string]any, len(dict.Keys))
+	for _, key := range dict.Keys {
+		entries[key] = FromValue(dict.Entries[key])
+	}
+	return entries
+}
+
+// hostFunction wraps a Go function so Kwenda code can call it
+func hostFunction(name string, fn func(args ...any) (any, error)) *interpreter.HostFunction {
+	return &interpreter.HostFunction{
+		Name: name,
+		Impl: func(call *interpreter.BuiltinCall) (interpreter.Value, error) {
+			args := make([]any, len(call.Args))
+			for i, arg := range call.Args {
+				args[i] = FromValue(arg)
+			}
+			result, err := fn(args...)
+			if err != nil {
+				if e, ok := err.(interpreter.ErrorValue); ok {
+					return nil, e
+				}
+				return nil, interpreter.ErrorValue{Message: err.Error(), Context: fmt.Sprintf("In '%s'", call.Name), Pos: call.Pos}
+			}
+			value, err := ToValue(result)
+			if err != nil {
+				return nil, interpreter.ErrorValue{Message: err.Error(), Context: fmt.Sprintf("In '%s'", call.Name), Pos: call.Pos}
+			}
+			return value, nil
+		},
+	}
+}
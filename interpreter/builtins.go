@@ -0,0 +1,627 @@
+package interpreter
+
+import (
+	"fmt"
+	"kwenda/ast"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BuiltinCall is what a built-in function receives when a program calls it
+type BuiltinCall struct {
+	Name string       // Name the function was called by
+	Args []Value      // Evaluated arguments
+	Pos  ast.Pos      // Position of the call, for errors
+	Env  *Environment // Scope of the caller, with the program's input and output
+
+	run bodyRunner // How the engine making the call runs function bodies
+}
+
+// Call calls a function the program passed to the built-in, such as a lambda or a
+// named kazi, with the engine that is running the program
+func (call *BuiltinCall) Call(function Value, args ...Value) (Value, error) {
+	run := call.run
+	if run == nil {
+		run = callFunction
+	}
+	return callValue(function, "", args, call.Pos, call.Env, run)
+}
+
+// builtinError is an error raised by the built-in being called, with a Swahili
+// message and an English context naming the function
+func builtinError(call *BuiltinCall, message, english string) error {
+	return ErrorValue{
+		Message: message,
+		Context: fmt.Sprintf("In '%s': %s", call.Name, english),
+		Pos:     call.Pos,
+	}
+}
+
+// BuiltinFunc implements a built-in function
+type BuiltinFunc func(call *BuiltinCall) (Value, error)
+
+// Builtin describes a function the runtime provides to every program. Programs
+// call built-ins like any other function; a user function or lambda with the
+// same name hides the built-in.
+type Builtin struct {
+	Name       string      // Name programs call it by
+	MinArgs    int         // Fewest arguments accepted
+	MaxArgs    int         // Most arguments accepted, or -1 for any number
+	ArgTypes   []string    // Type of each argument ("" accepts anything); the last one repeats
+	ReturnType string      // Type of the result ("" when it depends on the arguments)
+	Doc        string      // Swahili description
+	DocEnglish string      // English description
+	Impl       BuiltinFunc // Go implementation
+}
+
+// Accepts reports whether the built-in can be called with argc arguments
+func (b *Builtin) Accepts(argc int) bool {
+	return argc >= b.MinArgs && (b.MaxArgs < 0 || argc <= b.MaxArgs)
+}
+
+// Call checks the arguments against ArgTypes and runs the built-in. An argument of
+// the wrong type is an error, so Impl only needs to check what ArgTypes cannot say.
+func (b *Builtin) Call(call *BuiltinCall) (Value, error) {
+	for i, arg := range call.Args {
+		want := b.ArgType(i)
+		if want == "" {
+			continue
+		}
+		// Only the kind of a collection is checked, so orodha namba accepts any orodha
+		if kind, _, _ := strings.Cut(want, " "); arg.Type() != kind {
+			return nil, ErrorValue{
+				Message: fmt.Sprintf("Hoja ya %d ya '%s' lazima iwe %s, lakini ni %s", i+1, call.Name, want, arg.Type()),
+				Context: fmt.Sprintf("'%s' takes %s as argument %d, but was given %s", call.Name, want, i+1, arg.Type()),
+				Pos:     call.Pos,
+			}
+		}
+	}
+	return b.Impl(call)
+}
+
+// ArgType returns the declared type of argument i ("" if it accepts anything)
+func (b *Builtin) ArgType(i int) string {
+	if len(b.ArgTypes) == 0 {
+		return ""
+	}
+	if i >= len(b.ArgTypes) {
+		i = len(b.ArgTypes) - 1
+	}
+	return b.ArgTypes[i]
+}
+
+// Signature formats the built-in as name(types) result, marking optional
+// arguments with '?' and repeated ones with '...'
+func (b *Builtin) Signature() string {
+	count := b.MaxArgs
+	if count < 0 {
+		count = b.MinArgs
+		if count == 0 {
+			count = 1
+		}
+	}
+	params := make([]string, count)
+	for i := range params {
+		params[i] = b.ArgType(i)
+		if params[i] == "" {
+			params[i] = "thamani"
+		}
+		if i >= b.MinArgs {
+			params[i] += "?"
+		}
+	}
+	if b.MaxArgs < 0 {
+		params[count-1] = strings.TrimSuffix(params[count-1], "?") + "..."
+	}
+	signature := b.Name + "(" + strings.Join(params, ", ") + ")"
+	if b.ReturnType != "" {
+		signature += " " + b.ReturnType
+	}
+	return signature
+}
+
+var (
+	builtinsMu sync.RWMutex
+	builtins   = make(map[string]*Builtin)
+)
+
+// RegisterBuiltin adds a built-in function, replacing any with the same name.
+// Go code can call it to give every Kwenda program a new function.
+func RegisterBuiltin(b Builtin) {
+	builtinsMu.Lock()
+	defer builtinsMu.Unlock()
+	builtins[b.Name] = &b
+}
+
+// LookupBuiltin finds the built-in called name if it accepts argc arguments
+func LookupBuiltin(name string, argc int) (*Builtin, bool) {
+	b, exists := findBuiltin(name)
+	if !exists || !b.Accepts(argc) {
+		return nil, false
+	}
+	return b, true
+}
+
+// findBuiltin finds the built-in called name whatever arguments it is given
+func findBuiltin(name string) (*Builtin, bool) {
+	builtinsMu.RLock()
+	defer builtinsMu.RUnlock()
+	b, exists := builtins[name]
+	return b, exists
+}
+
+// Builtins lists every registered built-in, sorted by name
+func Builtins() []*Builtin {
+	builtinsMu.RLock()
+	defer builtinsMu.RUnlock()
+	list := make([]*Builtin, 0, len(builtins))
+	for _, b := range builtins {
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func init() {
+	for _, b := range coreBuiltins {
+		RegisterBuiltin(b)
+	}
+}
+
+// maxRange is the most numbers masafa makes, so a mistyped bound cannot use up all memory
+const maxRange = 10_000_000
+
+// coreBuiltins are the functions every program has: output, arrays, files and text
+var coreBuiltins = []Builtin{
+	{
+		Name: "andika", MinArgs: 0, MaxArgs: -1,
+		Doc:        "Andika thamani kwenye skrini, zikitenganishwa na nafasi",
+		DocEnglish: "print values separated by spaces",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			parts := make([]string, len(call.Args))
+			for i, arg := range call.Args {
+				parts[i] = arg.String()
+			}
+			fmt.Fprintln(call.Env.Streams.Stdout, strings.Join(parts, " "))
+			return Nil, nil
+		},
+	},
+
+	// Input
+	{
+		Name: "ingiza", MinArgs: 0, MaxArgs: 1, ReturnType: "maneno",
+		Doc:        "Soma mstari mzima wa maandishi, baada ya kuonyesha ujumbe; maneno matupu mwisho wa ingizo",
+		DocEnglish: "read a whole line of text after showing a prompt; empty text at the end of the input",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			showPrompt(call)
+			line, _ := call.Env.Streams.ReadLine()
+			return StringValue(line), nil
+		},
+	},
+	{
+		Name: "ingiza_namba", MinArgs: 0, MaxArgs: 1, ReturnType: "namba",
+		Doc:        "Soma namba, ukiuliza tena mpaka namba sahihi iingizwe",
+		DocEnglish: "read a number, asking again until a valid one is entered",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			return readValid(call, "namba", "a number", parseNumberInput)
+		},
+	},
+	{
+		Name: "ingiza_boolean", MinArgs: 0, MaxArgs: 1, ReturnType: "boolean",
+		Doc:        "Soma kweli au uwongo (pia ndiyo/hapana), ukiuliza tena mpaka jibu sahihi liingizwe",
+		DocEnglish: "read kweli or uwongo (also ndiyo/hapana), asking again until a valid answer is entered",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			return readValid(call, "kweli au uwongo", "kweli or uwongo", parseBooleanInput)
+		},
+	},
+
+	// Array manipulation functions
+	{
+		Name: "ongeza", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"orodha", ""}, ReturnType: "namba",
+		Doc:        "Ongeza kipengele mwisho wa orodha; inarudisha urefu mpya",
+		DocEnglish: "append an element to an array; returns the new length",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			arr := call.Args[0].(*ArrayValue)
+			arr.Elements = append(arr.Elements, call.Args[1])
+			return Int(len(arr.Elements)), nil
+		},
+	},
+	{
+		Name: "ondoa", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"orodha", "namba"}, ReturnType: "namba",
+		Doc:        "Ondoa kipengele kwenye index; inarudisha urefu mpya",
+		DocEnglish: "remove the element at an index; returns the new length",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			arr := call.Args[0].(*ArrayValue)
+			if idx := call.Args[1].(NumberValue); !idx.IsFloat && idx.Int >= 0 && idx.Int < len(arr.Elements) {
+				arr.Elements = append(arr.Elements[:idx.Int], arr.Elements[idx.Int+1:]...)
+				return Int(len(arr.Elements)), nil
+			}
+			return Int(0), nil
+		},
+	},
+	{
+		Name: "urefu_orodha", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"orodha"}, ReturnType: "namba",
+		Doc:        "Idadi ya vipengele kwenye orodha",
+		DocEnglish: "number of elements in an array",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			return Int(len(call.Args[0].(*ArrayValue).Elements)), nil
+		},
+	},
+	{
+		Name: "pata", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"orodha", "namba"},
+		Doc:        "Pata kipengele kwenye index; index nje ya mipaka ni hitilafu",
+		DocEnglish: "get the element at an index; an index out of range is an error",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			arr := call.Args[0].(*ArrayValue)
+			idx := call.Args[1].(NumberValue)
+			if idx.IsFloat {
+				return nil, builtinError(call, "Index lazima iwe namba kamili", "the index must be a whole number")
+			}
+			if idx.Int < 0 || idx.Int >= len(arr.Elements) {
+				return nil, builtinError(call,
+					fmt.Sprintf("Index %d ni nje ya mipaka ya orodha (urefu: %d)", idx.Int, len(arr.Elements)),
+					fmt.Sprintf("index %d is out of range; use an index from 0 to %d", idx.Int, len(arr.Elements)-1))
+			}
+			return arr.Elements[idx.Int], nil
+		},
+	},
+	{
+		Name: "masafa", MinArgs: 1, MaxArgs: 3, ArgTypes: []string{"namba"}, ReturnType: "orodha namba",
+		Doc:        "Orodha ya namba kutoka mwanzo (0 kama haupo) hadi kabla ya mwisho, kwa hatua (1 kama haipo)",
+		DocEnglish: "numbers from a start (0 if omitted) up to but not including an end, by a step (1 if omitted)",
+		Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+			bounds := []int{0, 0, 1}
+			if len(x) == 1 {
+				x = []NumberValue{Int(0), x[0]}
+			}
+			for i, n := range x {
+				if n.IsFloat {
+					return nil, builtinError(call, fmt.Sprintf("Hoja ya %d lazima iwe namba kamili", i+1),
+						fmt.Sprintf("argument %d must be a whole number", i+1))
+				}
+				bounds[i] = n.Int
+			}
+			start, end, step := bounds[0], bounds[1], bounds[2]
+			if step == 0 {
+				return nil, builtinError(call, "Hatua haiwezi kuwa sifuri", "the step cannot be zero")
+			}
+
+			count := 0
+			if step > 0 && end > start {
+				count = (end - start + step - 1) / step
+			} else if step < 0 && end < start {
+				count = (start - end - step - 1) / -step
+			}
+			if count > maxRange {
+				return nil, builtinError(call, fmt.Sprintf("Masafa yana namba %d, zaidi ya %d zinazoruhusiwa", count, maxRange),
+					fmt.Sprintf("the range has %d numbers, more than the %d allowed", count, maxRange))
+			}
+			elements := make([]Value, count)
+			for i := range elements {
+				elements[i] = Int(start + i*step)
+			}
+			return NewArray(elements), nil
+		}),
+	},
+
+	// File I/O operations
+	{
+		Name: "soma", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"maneno"}, ReturnType: "maneno",
+		Doc:        "Soma maudhui yote ya faili",
+		DocEnglish: "read the whole contents of a file",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			filename := call.Args[0].(StringValue)
+			content, err := os.ReadFile(string(filename))
+			if err != nil {
+				return nil, builtinError(call,
+					fmt.Sprintf("Hitilafu ya kusoma faili '%s': %v", filename, err),
+					"check that the file exists and that you may read it")
+			}
+			return StringValue(content), nil
+		},
+	},
+	{
+		Name: "andika_faili", MinArgs: 2, MaxArgs: 3, ArgTypes: []string{"maneno", "", "boolean"}, ReturnType: "boolean",
+		Doc:        "Andika maandishi kwenye faili; kweli kama hoja ya tatu huongeza mwishoni",
+		DocEnglish: "write text to a file; a third argument of kweli appends instead",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			filename := call.Args[0].(StringValue)
+			content := call.Args[1].String()
+
+			// Check if append mode is specified
+			appendMode := len(call.Args) == 3 && bool(call.Args[2].(BoolValue))
+
+			var err error
+			if appendMode {
+				file, openErr := os.OpenFile(string(filename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+				if openErr != nil {
+					fmt.Fprintf(call.Env.Streams.Stderr, "Hitilafu ya kufungua faili '%s': %v\n", filename, openErr)
+					return BoolValue(false), nil
+				}
+				defer file.Close()
+				_, err = file.WriteString(content)
+			} else {
+				err = os.WriteFile(string(filename), []byte(content), 0644)
+			}
+			if err != nil {
+				fmt.Fprintf(call.Env.Streams.Stderr, "Hitilafu ya kuandika faili '%s': %v\n", filename, err)
+				return BoolValue(false), nil
+			}
+			return BoolValue(true), nil
+		},
+	},
+	{
+		Name: "unda_faili", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"maneno"}, ReturnType: "boolean",
+		Doc:        "Unda faili tupu",
+		DocEnglish: "create an empty file",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			filename := call.Args[0].(StringValue)
+			file, err := os.Create(string(filename))
+			if err != nil {
+				fmt.Fprintf(call.Env.Streams.Stderr, "Hitilafu ya kuunda faili '%s': %v\n", filename, err)
+				return BoolValue(false), nil
+			}
+			file.Close()
+			return BoolValue(true), nil
+		},
+	},
+	{
+		Name: "faili_ipo", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"maneno"}, ReturnType: "boolean",
+		Doc:        "Angalia kama faili ipo",
+		DocEnglish: "check whether a file exists",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			_, err := os.Stat(string(call.Args[0].(StringValue)))
+			return BoolValue(err == nil), nil
+		},
+	},
+	{
+		Name: "ondoa_faili", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"maneno"}, ReturnType: "boolean",
+		Doc:        "Futa faili",
+		DocEnglish: "delete a file",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			filename := call.Args[0].(StringValue)
+			if err := os.Remove(string(filename)); err != nil {
+				fmt.Fprintf(call.Env.Streams.Stderr, "Hitilafu ya kuondoa faili '%s': %v\n", filename, err)
+				return BoolValue(false), nil
+			}
+			return BoolValue(true), nil
+		},
+	},
+
+	// Environment variables
+	{
+		Name: "pata_mazingira", MinArgs: 1, MaxArgs: 2, ArgTypes: []string{"maneno", "maneno"}, ReturnType: "maneno",
+		Doc:        "Thamani ya kigeu cha mazingira; hoja ya pili (au maneno matupu) kama hakipo",
+		DocEnglish: "value of an environment variable; the second argument (or empty text) if it is not set",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			name, err := environmentName(call)
+			if err != nil {
+				return nil, err
+			}
+			if value, exists := os.LookupEnv(name); exists {
+				return StringValue(value), nil
+			}
+			if len(call.Args) == 2 {
+				return call.Args[1], nil
+			}
+			return StringValue(""), nil
+		},
+	},
+	{
+		Name: "weka_mazingira", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"maneno", ""}, ReturnType: "boolean",
+		Doc:        "Weka kigeu cha mazingira kwa programu hii na programu inazoanzisha",
+		DocEnglish: "set an environment variable for this program and the programs it starts",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			name, err := environmentName(call)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.Setenv(name, call.Args[1].String()); err != nil {
+				fmt.Fprintf(call.Env.Streams.Stderr, "Hitilafu ya kuweka kigeu cha mazingira '%s': %v\n", name, err)
+				return BoolValue(false), nil
+			}
+			return BoolValue(true), nil
+		},
+	},
+	{
+		Name: "mazingira", MinArgs: 0, MaxArgs: 0, ReturnType: "kamusi",
+		Doc:        "Vigeu vyote vya mazingira kama kamusi, vimepangwa kwa jina",
+		DocEnglish: "every environment variable as a kamusi, sorted by name",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			variables := os.Environ()
+			sort.Strings(variables)
+			dict := NewDict()
+			for _, variable := range variables {
+				// Windows keeps per-drive directories in variables named like "=C:"
+				name, value, _ := strings.Cut(variable, "=")
+				if name != "" {
+					dict.Set(name, StringValue(value))
+				}
+			}
+			return dict, nil
+		},
+	},
+
+	// String manipulation functions
+	{
+		Name: "urefu", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"maneno"}, ReturnType: "namba",
+		Doc:        "Idadi ya herufi kwenye maneno",
+		DocEnglish: "length of a string",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			return Int(len(call.Args[0].(StringValue))), nil
+		},
+	},
+	{
+		Name: "unganisha", MinArgs: 2, MaxArgs: -1, ReturnType: "maneno",
+		Doc:        "Unganisha thamani zote kuwa maneno moja",
+		DocEnglish: "join values together as text",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			var result strings.Builder
+			for _, arg := range call.Args {
+				result.WriteString(arg.String())
+			}
+			return StringValue(result.String()), nil
+		},
+	},
+	{
+		Name: "kata", MinArgs: 2, MaxArgs: 3, ArgTypes: []string{"maneno", "namba", "namba"}, ReturnType: "maneno",
+		Doc:        "Sehemu ya maneno kuanzia index, kwa urefu ukipenda",
+		DocEnglish: "substring from an index, with an optional length",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			str := call.Args[0].(StringValue)
+			start := call.Args[1].(NumberValue)
+			if start.IsFloat || start.Int < 0 || start.Int >= len(str) {
+				return StringValue(""), nil
+			}
+			if len(call.Args) == 2 {
+				return str[start.Int:], nil
+			}
+			if length := call.Args[2].(NumberValue); !length.IsFloat {
+				end := start.Int + length.Int
+				if end > len(str) {
+					end = len(str)
+				}
+				return str[start.Int:end], nil
+			}
+			return StringValue(""), nil
+		},
+	},
+	{
+		Name: "badilisha", MinArgs: 3, MaxArgs: 3, ArgTypes: []string{"maneno"}, ReturnType: "maneno",
+		Doc:        "Badilisha kila sehemu inayolingana na maneno mengine",
+		DocEnglish: "replace every occurrence of a substring",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			str, old, replacement := call.Args[0].(StringValue), call.Args[1].(StringValue), call.Args[2].(StringValue)
+			return StringValue(strings.ReplaceAll(string(str), string(old), string(replacement))), nil
+		},
+	},
+	{
+		Name: "tafuta", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"maneno"}, ReturnType: "namba",
+		Doc:        "Index ya kwanza ya sehemu ndani ya maneno, au -1",
+		DocEnglish: "index of the first occurrence of a substring, or -1",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			str, substr := call.Args[0].(StringValue), call.Args[1].(StringValue)
+			return Int(strings.Index(string(str), string(substr))), nil
+		},
+	},
+	{
+		Name: "awali", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"maneno"}, ReturnType: "boolean",
+		Doc:        "Angalia kama maneno yanaanza na kiambishi",
+		DocEnglish: "check whether a string starts with a prefix",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			str, prefix := call.Args[0].(StringValue), call.Args[1].(StringValue)
+			return BoolValue(strings.HasPrefix(string(str), string(prefix))), nil
+		},
+	},
+	{
+		Name: "mwisho", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"maneno"}, ReturnType: "boolean",
+		Doc:        "Angalia kama maneno yanaishia na kiambishi",
+		DocEnglish: "check whether a string ends with a suffix",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			str, suffix := call.Args[0].(StringValue), call.Args[1].(StringValue)
+			return BoolValue(strings.HasSuffix(string(str), string(suffix))), nil
+		},
+	},
+	{
+		Name: "herufi_kubwa", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"maneno"}, ReturnType: "maneno",
+		Doc:        "Badilisha kuwa herufi kubwa",
+		DocEnglish: "convert to upper case",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			return StringValue(strings.ToUpper(string(call.Args[0].(StringValue)))), nil
+		},
+	},
+	{
+		Name: "herufi_ndogo", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"maneno"}, ReturnType: "maneno",
+		Doc:        "Badilisha kuwa herufi ndogo",
+		DocEnglish: "convert to lower case",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			return StringValue(strings.ToLower(string(call.Args[0].(StringValue)))), nil
+		},
+	},
+	{
+		Name: "ondoa_nafasi", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"maneno"}, ReturnType: "maneno",
+		Doc:        "Ondoa nafasi mwanzoni na mwishoni",
+		DocEnglish: "trim leading and trailing whitespace",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			return StringValue(strings.TrimSpace(string(call.Args[0].(StringValue)))), nil
+		},
+	},
+	{
+		Name: "gawanya_maneno", MinArgs: 1, MaxArgs: 2, ArgTypes: []string{"maneno"}, ReturnType: "namba",
+		Doc:        "Idadi ya sehemu baada ya kugawanya kwa nafasi au kitenganishi",
+		DocEnglish: "number of parts after splitting on whitespace or a separator",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			// Returns the number of parts for now (could be enhanced to return array)
+			str := call.Args[0].(StringValue)
+			if len(call.Args) == 2 {
+				return Int(len(strings.Split(string(str), string(call.Args[1].(StringValue))))), nil
+			}
+			return Int(len(strings.Fields(string(str)))), nil
+		},
+	},
+}
+
+// environmentName returns the variable name given to an environment built-in
+func environmentName(call *BuiltinCall) (string, error) {
+	name, ok := call.Args[0].(StringValue)
+	if !ok || name == "" {
+		return "", builtinError(call,
+			fmt.Sprintf("Jina la kigeu cha mazingira si sahihi: '%s'", call.Args[0]),
+			"the name of an environment variable must be non-empty maneno")
+	}
+	return string(name), nil
+}
+
+// showPrompt writes the prompt given to an input built-in, or a default one
+func showPrompt(call *BuiltinCall) {
+	prompt := "Ingiza thamani:"
+	if len(call.Args) == 1 {
+		prompt = call.Args[0].String()
+	}
+	fmt.Fprint(call.Env.Streams.Stdout, prompt+" ")
+}
+
+// readValid prompts for lines of input until parse accepts one. Running out of
+// input first is an error, since there is no value to return.
+func readValid(call *BuiltinCall, want, wantEnglish string, parse func(string) (Value, bool)) (Value, error) {
+	for {
+		showPrompt(call)
+		line, ok := call.Env.Streams.ReadLine()
+		if !ok {
+			return nil, builtinError(call,
+				fmt.Sprintf("Ingizo limeisha kabla ya kupata %s", want),
+				fmt.Sprintf("the input ended before %s was entered", wantEnglish))
+		}
+		if value, ok := parse(strings.TrimSpace(line)); ok {
+			return value, nil
+		}
+		fmt.Fprintf(call.Env.Streams.Stdout, "'%s' si jibu sahihi; andika %s\n", strings.TrimSpace(line), want)
+	}
+}
+
+// parseNumberInput reads a whole number or a decimal typed by the user
+func parseNumberInput(text string) (Value, bool) {
+	if value, err := strconv.Atoi(text); err == nil {
+		return Int(value), true
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, false
+	}
+	return Float(value), true
+}
+
+// parseBooleanInput reads a yes or no answer typed by the user, in Swahili or English
+func parseBooleanInput(text string) (Value, bool) {
+	switch strings.ToLower(text) {
+	case "kweli", "ndiyo", "ndio", "true", "yes":
+		return BoolValue(true), true
+	case "uwongo", "hapana", "false", "no":
+		return BoolValue(false), true
+	}
+	return nil, false
+}
@@ -0,0 +1,108 @@
+package interpreter
+
+import (
+	"fmt"
+	"kwenda/ast"
+	"strings"
+)
+
+// Opcode is a single VM instruction. Operands follow the opcode as 16-bit
+// big-endian integers; operandCounts lists how many each opcode takes.
+type Opcode byte
+
+const (
+	OpConstant      Opcode = iota // push Constants[a]
+	OpNil                         // push nil
+	OpPop                         // discard the top of the stack
+	OpPopResult                   // pop into the statement result (the value a function returns without rudisha)
+	OpClearResult                 // set the statement result to nil
+	OpSaveResult                  // push the statement result
+	OpRestoreResult               // pop into the statement result without changing it otherwise
+	OpLoad                        // push the value of Names[a]
+	OpStore                       // store the top of the stack in variable Names[a], leaving it there
+	OpThis                        // push hii
+	OpBinary                      // pop right, left; push left Names[a] right
+	OpUnary                       // pop operand; push Names[a] operand
+	OpJump                        // jump to a
+	OpJumpIfFalse                 // pop; jump to a if it is false
+	OpArray                       // pop a elements; push an array of them
+	OpDict                        // pop a key/value pairs; push a dictionary of them
+	OpIndex                       // pop index, container; push container[index]
+	OpSetIndex                    // pop value, index, container; store; push the stored value
+	OpMember                      // pop object; push object.Names[a]
+	OpSetMember                   // pop value, object; store object.Names[a]; push the stored value
+	OpUpdateIndex                 // pop value, index, container; store container[index] Names[a] value; push it
+	OpUpdateMember                // pop value, object; store object.Names[a] Names[b] value; push it
+	OpCall                        // pop b arguments; call the lambda, function or built-in Names[a]
+	OpGetMethod                   // pop object; push the method Names[a] bound to it, or the function stored there
+	OpCallMethod                  // pop a arguments and a bound method or function; call it
+	OpNew                         // push a new instance of class Names[a] with default properties
+	OpConstruct                   // pop a arguments; run the constructor of the instance below them
+	OpClosure                     // push a lambda for Lambdas[a] closing over the current scope
+	OpReturn                      // pop and return from the function; a is 1 when rudisha returns
+	OpThrow                       // pop and throw
+	OpPushHandler                 // on error, restore the stack and scope and jump to a
+	OpPopHandler                  // discard the innermost handler
+	OpCaught                      // push the error being handled
+	OpPushScope                   // enter a child scope
+	OpPopScope                    // leave the current scope
+	OpDeclare                     // record the function or class Declarations[a]
+	OpIterate                     // pop a collection; push an iterator over it for a loop variables
+	OpNext                        // push the next key (with two loop variables) and item of the iterator on top; jump to a when done
+	OpMatch                       // push whether the top of the stack matches a pattern of Arms[a], storing its variables
+)
+
+var opcodeNames = [...]string{
+	"CONSTANT", "NIL", "POP", "POP_RESULT", "CLEAR_RESULT", "SAVE_RESULT", "RESTORE_RESULT",
+	"LOAD", "STORE", "THIS", "BINARY", "UNARY", "JUMP", "JUMP_IF_FALSE", "ARRAY", "DICT",
+	"INDEX", "SET_INDEX", "MEMBER", "SET_MEMBER", "UPDATE_INDEX", "UPDATE_MEMBER", "CALL", "GET_METHOD",
+	"CALL_METHOD", "NEW", "CONSTRUCT", "CLOSURE", "RETURN", "THROW",
+	"PUSH_HANDLER", "POP_HANDLER", "CAUGHT", "PUSH_SCOPE", "POP_SCOPE", "DECLARE", "ITERATE", "NEXT", "MATCH",
+}
+
+var operandCounts = [...]int{
+	OpConstant: 1, OpLoad: 1, OpStore: 1, OpBinary: 1, OpUnary: 1, OpJump: 1,
+	OpJumpIfFalse: 1, OpArray: 1, OpDict: 1, OpMember: 1, OpSetMember: 1,
+	OpUpdateIndex: 1, OpUpdateMember: 2,
+	OpCall: 2, OpGetMethod: 1, OpCallMethod: 1, OpNew: 1,
+	OpConstruct: 1, OpClosure: 1, OpReturn: 1, OpPushHandler: 1, OpDeclare: 1,
+	OpIterate: 1, OpNext: 1, OpMatch: 1,
+}
+
+func (op Opcode) String() string {
+	if int(op) < len(opcodeNames) {
+		return opcodeNames[op]
+	}
+	return fmt.Sprintf("OP(%d)", op)
+}
+
+// Code is a compiled function body or top-level statement
+type Code struct {
+	Instructions []byte
+	Positions    []ast.Pos // Source position of the instruction starting at each offset
+	Constants    []Value
+	Names        []string         // Variables, members, functions, classes and operators
+	Lambdas      []ast.LambdaNode // Lambda expressions created by OpClosure
+	Declarations []ast.ASTNode    // Functions and classes declared by OpDeclare
+	Arms         [][]ast.ASTNode  // Patterns of the chagua arms tried by OpMatch
+}
+
+// String disassembles the code, one instruction per line
+func (c *Code) String() string {
+	var out strings.Builder
+	for offset := 0; offset < len(c.Instructions); {
+		op := Opcode(c.Instructions[offset])
+		fmt.Fprintf(&out, "%04d %s", offset, op)
+		for i := 0; i < operandCounts[op]; i++ {
+			fmt.Fprintf(&out, " %d", c.operand(offset+1+2*i))
+		}
+		out.WriteString("\n")
+		offset += 1 + 2*operandCounts[op]
+	}
+	return out.String()
+}
+
+// operand decodes the 16-bit operand at offset
+func (c *Code) operand(offset int) int {
+	return int(c.Instructions[offset])<<8 | int(c.Instructions[offset+1])
+}
@@ -0,0 +1,277 @@
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+)
+
+func init() {
+	for _, b := range collectionBuiltins {
+		RegisterBuiltin(b)
+	}
+}
+
+// collectionBuiltins work on whole arrays. The ones taking a kazi accept a lambda
+// or the name of a kazi, and none of them change the array they are given.
+var collectionBuiltins = []Builtin{
+	{
+		Name: "ramani", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"orodha", "kazi"}, ReturnType: "orodha",
+		Doc:        "Orodha mpya ya matokeo ya kazi kwa kila kipengele",
+		DocEnglish: "a new array of the results of a function applied to each element (map)",
+		Impl: arrayFunc(func(call *BuiltinCall, elements []Value, function Value) (Value, error) {
+			results := make([]Value, len(elements))
+			for i, element := range elements {
+				result, err := call.Call(function, element)
+				if err != nil {
+					return nil, err
+				}
+				results[i] = result
+			}
+			return NewArray(results), nil
+		}),
+	},
+	{
+		Name: "chuja", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"orodha", "kazi"}, ReturnType: "orodha",
+		Doc:        "Orodha mpya ya vipengele ambavyo kazi inarudisha kweli kwavyo",
+		DocEnglish: "a new array of the elements for which a function returns kweli (filter)",
+		Impl: arrayFunc(func(call *BuiltinCall, elements []Value, function Value) (Value, error) {
+			kept := []Value{}
+			for _, element := range elements {
+				keep, err := call.Call(function, element)
+				if err != nil {
+					return nil, err
+				}
+				if toBool(keep) {
+					kept = append(kept, element)
+				}
+			}
+			return NewArray(kept), nil
+		}),
+	},
+	{
+		Name: "punguza", MinArgs: 2, MaxArgs: 3, ArgTypes: []string{"orodha", "kazi", ""},
+		Doc:        "Unganisha vipengele kuwa thamani moja: kazi inapewa jumla ya sasa na kipengele; hoja ya tatu ni thamani ya mwanzo",
+		DocEnglish: "combine the elements into one value: the function gets the running total and an element; a third argument is the starting value (reduce)",
+		Impl: arrayFunc(func(call *BuiltinCall, elements []Value, function Value) (Value, error) {
+			var total Value
+			if len(call.Args) == 3 {
+				total = call.Args[2]
+			} else if len(elements) == 0 {
+				return nil, builtinError(call, "Orodha tupu haina thamani ya mwanzo; toa hoja ya tatu",
+					"an empty array has no starting value; pass a third argument")
+			} else {
+				total, elements = elements[0], elements[1:]
+			}
+			for _, element := range elements {
+				var err error
+				if total, err = call.Call(function, total, element); err != nil {
+					return nil, err
+				}
+			}
+			return total, nil
+		}),
+	},
+	{
+		Name: "kila", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"orodha", "kazi"}, ReturnType: "boolean",
+		Doc:        "kweli kama kazi inarudisha kweli kwa kila kipengele (kweli kwa orodha tupu)",
+		DocEnglish: "kweli if a function returns kweli for every element (kweli for an empty array)",
+		Impl: arrayFunc(func(call *BuiltinCall, elements []Value, function Value) (Value, error) {
+			index, err := findFirst(call, elements, function, false)
+			return BoolValue(index < 0), err
+		}),
+	},
+	{
+		Name: "baadhi", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"orodha", "kazi"}, ReturnType: "boolean",
+		Doc:        "kweli kama kazi inarudisha kweli kwa angalau kipengele kimoja",
+		DocEnglish: "kweli if a function returns kweli for at least one element",
+		Impl: arrayFunc(func(call *BuiltinCall, elements []Value, function Value) (Value, error) {
+			index, err := findFirst(call, elements, function, true)
+			return BoolValue(index >= 0), err
+		}),
+	},
+	{
+		Name: "tafuta_kwanza", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"orodha", "kazi"},
+		Doc:        "Kipengele cha kwanza ambacho kazi inarudisha kweli kwacho, au tupu kama hakipo",
+		DocEnglish: "the first element for which a function returns kweli, or tupu if there is none",
+		Impl: arrayFunc(func(call *BuiltinCall, elements []Value, function Value) (Value, error) {
+			index, err := findFirst(call, elements, function, true)
+			if err != nil || index < 0 {
+				return Nil, err
+			}
+			return elements[index], nil
+		}),
+	},
+	{
+		Name: "panga", MinArgs: 1, MaxArgs: 2, ArgTypes: []string{"orodha", "kazi"}, ReturnType: "orodha",
+		Doc:        "Orodha mpya iliyopangwa; kazi ya hiari inapewa vipengele viwili na kurudisha kweli kama cha kwanza kinatangulia",
+		DocEnglish: "a new sorted array; an optional function gets two elements and returns kweli if the first comes before the second",
+		Impl: arrayFunc(func(call *BuiltinCall, elements []Value, function Value) (Value, error) {
+			sorted := append([]Value(nil), elements...)
+			var err error
+			sort.SliceStable(sorted, func(i, j int) bool {
+				if err != nil {
+					return false
+				}
+				var before bool
+				before, err = comesBefore(call, function, sorted[i], sorted[j])
+				return before
+			})
+			if err != nil {
+				return nil, err
+			}
+			return NewArray(sorted), nil
+		}),
+	},
+	{
+		Name: "geuza", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"orodha"}, ReturnType: "orodha",
+		Doc:        "Orodha mpya yenye vipengele kwa mpangilio wa kinyume",
+		DocEnglish: "a new array with the elements in reverse order",
+		Impl: arrayFunc(func(call *BuiltinCall, elements []Value, _ Value) (Value, error) {
+			reversed := make([]Value, len(elements))
+			for i, element := range elements {
+				reversed[len(elements)-1-i] = element
+			}
+			return NewArray(reversed), nil
+		}),
+	},
+	{
+		Name: "unganisha_orodha", MinArgs: 1, MaxArgs: -1, ArgTypes: []string{"orodha"}, ReturnType: "orodha",
+		Doc:        "Orodha mpya yenye vipengele vya orodha zote kwa mfuatano",
+		DocEnglish: "a new array with the elements of every array in turn (concatenate)",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			joined := []Value{}
+			for i := range call.Args {
+				array, err := arrayArg(call, i)
+				if err != nil {
+					return nil, err
+				}
+				joined = append(joined, array.Elements...)
+			}
+			return NewArray(joined), nil
+		},
+	},
+	{
+		Name: "zip", MinArgs: 2, MaxArgs: -1, ArgTypes: []string{"orodha"}, ReturnType: "orodha orodha",
+		Doc:        "Orodha ya jozi za vipengele vilivyo kwenye nafasi moja; urefu ni wa orodha fupi zaidi",
+		DocEnglish: "an array of the elements at the same position grouped together; as long as the shortest array",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			arrays := make([]*ArrayValue, len(call.Args))
+			shortest := -1
+			for i := range call.Args {
+				array, err := arrayArg(call, i)
+				if err != nil {
+					return nil, err
+				}
+				arrays[i] = array
+				if shortest < 0 || len(array.Elements) < shortest {
+					shortest = len(array.Elements)
+				}
+			}
+			groups := make([]Value, shortest)
+			for i := range groups {
+				group := make([]Value, len(arrays))
+				for j, array := range arrays {
+					group[j] = array.Elements[i]
+				}
+				groups[i] = NewArray(group)
+			}
+			return NewArray(groups), nil
+		},
+	},
+	{
+		Name: "gawanya_vipande", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"orodha", "namba"}, ReturnType: "orodha orodha",
+		Doc:        "Gawanya orodha kuwa vipande vya ukubwa uliotolewa; kipande cha mwisho kinaweza kuwa kifupi",
+		DocEnglish: "split an array into pieces of a given size; the last piece may be shorter (chunk)",
+		Impl: func(call *BuiltinCall) (Value, error) {
+			array, err := arrayArg(call, 0)
+			if err != nil {
+				return nil, err
+			}
+			size, ok := call.Args[1].(NumberValue)
+			if !ok || size.IsFloat || size.Int < 1 {
+				return nil, builtinError(call, "Ukubwa wa kipande lazima uwe namba kamili chanya",
+					"the piece size must be a positive whole number")
+			}
+			pieces := []Value{}
+			for start := 0; start < len(array.Elements); start += size.Int {
+				end := min(start+size.Int, len(array.Elements))
+				pieces = append(pieces, NewArray(append([]Value(nil), array.Elements[start:end]...)))
+			}
+			return NewArray(pieces), nil
+		},
+	},
+}
+
+// arrayFunc checks that the first argument is an array and the second, if there
+// is one, a function, before calling impl with the array's elements
+func arrayFunc(impl func(call *BuiltinCall, elements []Value, function Value) (Value, error)) BuiltinFunc {
+	return func(call *BuiltinCall) (Value, error) {
+		array, err := arrayArg(call, 0)
+		if err != nil {
+			return nil, err
+		}
+		var function Value
+		if len(call.Args) > 1 {
+			switch call.Args[1].(type) {
+			case *FunctionValue, *HostFunction:
+				function = call.Args[1]
+			default:
+				return nil, builtinError(call,
+					fmt.Sprintf("Hoja ya 2 lazima iwe kazi, lakini ni %s", call.Args[1].Type()),
+					fmt.Sprintf("argument 2 must be a function, but is %s", call.Args[1].Type()))
+			}
+		}
+		return impl(call, array.Elements, function)
+	}
+}
+
+// arrayArg returns argument i, which must be an array
+func arrayArg(call *BuiltinCall, i int) (*ArrayValue, error) {
+	array, ok := call.Args[i].(*ArrayValue)
+	if !ok {
+		return nil, builtinError(call,
+			fmt.Sprintf("Hoja ya %d lazima iwe orodha, lakini ni %s", i+1, call.Args[i].Type()),
+			fmt.Sprintf("argument %d must be an array, but is %s", i+1, call.Args[i].Type()))
+	}
+	return array, nil
+}
+
+// findFirst returns the index of the first element for which function's result
+// is want, or -1 if there is none
+func findFirst(call *BuiltinCall, elements []Value, function Value, want bool) (int, error) {
+	for i, element := range elements {
+		result, err := call.Call(function, element)
+		if err != nil {
+			return -1, err
+		}
+		if toBool(result) == want {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// comesBefore reports whether a sorts before b: by the program's function if it
+// gave one, otherwise by the order of numbers or of text
+func comesBefore(call *BuiltinCall, function, a, b Value) (bool, error) {
+	if function != nil {
+		result, err := call.Call(function, a, b)
+		if err != nil {
+			return false, err
+		}
+		return toBool(result), nil
+	}
+	switch x := a.(type) {
+	case NumberValue:
+		if y, ok := b.(NumberValue); ok {
+			return x.AsFloat() < y.AsFloat(), nil
+		}
+	case StringValue:
+		if y, ok := b.(StringValue); ok {
+			return x < y, nil
+		}
+	}
+	return false, builtinError(call,
+		fmt.Sprintf("Haiwezekani kupanga %s pamoja na %s bila kazi ya kulinganisha", a.Type(), b.Type()),
+		fmt.Sprintf("cannot order %s and %s without a comparison function", a.Type(), b.Type()))
+}
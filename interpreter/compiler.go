@@ -0,0 +1,552 @@
+package interpreter
+
+import (
+	"fmt"
+	"kwenda/ast"
+)
+
+// maxOperand is the largest value a 16-bit operand can hold
+const maxOperand = 1<<16 - 1
+
+// unwindKind names something a jump out of a block has to undo
+type unwindKind int
+
+const (
+	unwindLoop    unwindKind = iota // Target of vunja and endelea
+	unwindScope                     // A scope entered with OpPushScope
+	unwindHandler                   // An error handler installed with OpPushHandler
+	unwindTemp                      // A value kept on the stack
+	unwindFinally                   // A hatimaye block that must run on the way out
+)
+
+type unwind struct {
+	kind    unwindKind
+	loop    *loopJumps    // For unwindLoop
+	finally []ast.ASTNode // For unwindFinally
+}
+
+// loopJumps collects the jumps that leave a loop, patched once their targets are known
+type loopJumps struct {
+	breaks    []int
+	continues []int
+}
+
+// compiler translates a function body or a statement into Code
+type compiler struct {
+	code    *Code
+	names   map[string]int
+	unwinds []unwind // Enclosing constructs, innermost last
+	pos     ast.Pos  // Position of the node being compiled
+	err     error
+}
+
+func newCompiler() *compiler {
+	return &compiler{code: &Code{}, names: make(map[string]int)}
+}
+
+// CompileBody compiles a function body. Running the code returns the value of
+// rudisha, or else the value of the last statement, like the tree-walker.
+func CompileBody(body []ast.ASTNode) (*Code, error) {
+	c := newCompiler()
+	for _, statement := range body {
+		c.topLevelStatement(statement)
+	}
+	c.emit(OpSaveResult)
+	c.emit(OpReturn, 0)
+	return c.code, c.err
+}
+
+// compileStatement compiles a single statement run on its own, such as a top-level declaration
+func compileStatement(node ast.ASTNode) (*Code, error) {
+	return CompileBody([]ast.ASTNode{node})
+}
+
+// compileExpression compiles an expression whose value the code returns
+func compileExpression(node ast.ASTNode) (*Code, error) {
+	c := newCompiler()
+	c.expression(node)
+	c.emit(OpReturn, 0)
+	return c.code, c.err
+}
+
+// topLevelStatement compiles a statement of a function body. vunja and endelea do not
+// escape a function: they only end the statement they appear in.
+func (c *compiler) topLevelStatement(node ast.ASTNode) {
+	jumps := &loopJumps{}
+	c.unwinds = append(c.unwinds, unwind{kind: unwindLoop, loop: jumps})
+	c.statement(node)
+	c.unwinds = c.unwinds[:len(c.unwinds)-1]
+	c.patchAll(jumps.breaks, len(c.code.Instructions))
+	c.patchAll(jumps.continues, len(c.code.Instructions))
+}
+
+// fail records the first compile error
+func (c *compiler) fail(message string, english string) {
+	if c.err == nil {
+		c.err = ErrorValue{Message: message, Context: english, Pos: c.pos}
+	}
+}
+
+// emit appends an instruction and returns the offset of its first operand
+func (c *compiler) emit(op Opcode, operands ...int) int {
+	code := c.code
+	code.Instructions = append(code.Instructions, byte(op))
+	code.Positions = append(code.Positions, c.pos)
+	start := len(code.Instructions)
+	for _, operand := range operands {
+		if operand < 0 || operand > maxOperand {
+			c.fail("Kazi ni kubwa mno kutafsiriwa", "function is too large to compile")
+			operand = 0
+		}
+		code.Instructions = append(code.Instructions, byte(operand>>8), byte(operand))
+		code.Positions = append(code.Positions, c.pos, c.pos)
+	}
+	return start
+}
+
+// patch sets the jump operand at offset to target
+func (c *compiler) patch(offset int, target int) {
+	if target > maxOperand {
+		c.fail("Kazi ni kubwa mno kutafsiriwa", "function is too large to compile")
+		return
+	}
+	c.code.Instructions[offset] = byte(target >> 8)
+	c.code.Instructions[offset+1] = byte(target)
+}
+
+func (c *compiler) patchAll(offsets []int, target int) {
+	for _, offset := range offsets {
+		c.patch(offset, target)
+	}
+}
+
+// name returns the index of name in the name table, adding it if needed
+func (c *compiler) name(name string) int {
+	if index, exists := c.names[name]; exists {
+		return index
+	}
+	index := len(c.code.Names)
+	c.code.Names = append(c.code.Names, name)
+	c.names[name] = index
+	return index
+}
+
+func (c *compiler) constant(value Value) int {
+	c.code.Constants = append(c.code.Constants, value)
+	return len(c.code.Constants) - 1
+}
+
+func (c *compiler) pushUnwind(u unwind) {
+	c.unwinds = append(c.unwinds, u)
+}
+
+func (c *compiler) popUnwind() {
+	c.unwinds = c.unwinds[:len(c.unwinds)-1]
+}
+
+// unwindTo emits the code that leaves every construct above depth: popping handlers,
+// scopes and temporaries and running finally blocks
+func (c *compiler) unwindTo(depth int) {
+	for i := len(c.unwinds) - 1; i >= depth; i-- {
+		switch u := c.unwinds[i]; u.kind {
+		case unwindScope:
+			c.emit(OpPopScope)
+		case unwindHandler:
+			c.emit(OpPopHandler)
+		case unwindTemp:
+			c.emit(OpPop)
+		case unwindFinally:
+			// The finally block runs outside the constructs it was inside
+			saved := c.unwinds
+			c.unwinds = append([]unwind(nil), saved[:i]...)
+			c.finallyBlock(u.finally)
+			c.unwinds = saved
+		}
+	}
+}
+
+// innermostLoop returns the depth of the nearest enclosing loop
+func (c *compiler) innermostLoop() int {
+	for i := len(c.unwinds) - 1; i >= 0; i-- {
+		if c.unwinds[i].kind == unwindLoop {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *compiler) block(statements []ast.ASTNode) {
+	for _, statement := range statements {
+		c.statement(statement)
+	}
+}
+
+// finallyBlock compiles a hatimaye block; it leaves the statement result untouched
+func (c *compiler) finallyBlock(statements []ast.ASTNode) {
+	c.emit(OpSaveResult)
+	c.pushUnwind(unwind{kind: unwindTemp})
+	c.block(statements)
+	c.popUnwind()
+	c.emit(OpRestoreResult)
+}
+
+// loopBody compiles the body of a loop and returns the jumps that leave it
+func (c *compiler) loopBody(body []ast.ASTNode) *loopJumps {
+	jumps := &loopJumps{}
+	c.pushUnwind(unwind{kind: unwindLoop, loop: jumps})
+	c.block(body)
+	c.popUnwind()
+	return jumps
+}
+
+func (c *compiler) statement(node ast.ASTNode) {
+	c.pos = ast.PosOf(node)
+	switch n := node.(type) {
+	case ast.VariableDeclarationNode:
+		c.declaration(n.Name, n.Value)
+
+	case ast.StringVariableDeclarationNode:
+		c.declaration(n.Name, n.Value)
+
+	case ast.ClassVariableDeclarationNode:
+		c.declaration(n.VarName, n.Value)
+
+	case ast.DictionaryDeclarationNode:
+		c.declaration(n.Name, n.Value)
+
+	case ast.ArrayDeclarationNode:
+		if n.Value != nil {
+			c.declaration(n.Name, n.Value)
+			return
+		}
+		c.expressions(n.Elements)
+		c.emit(OpArray, len(n.Elements))
+		c.emit(OpStore, c.name(n.Name))
+		c.emit(OpPopResult)
+
+	case ast.ArrayAssignmentNode:
+		c.expression(n.Array)
+		c.expression(n.Index)
+		c.expression(n.Value)
+		c.pos = n.Pos
+		if n.Op != "" {
+			c.emit(OpUpdateIndex, c.name(n.Op))
+		} else {
+			c.emit(OpSetIndex)
+		}
+		c.emit(OpPopResult)
+
+	case ast.MemberAssignmentNode:
+		c.expression(n.Object)
+		c.expression(n.Value)
+		c.pos = n.Pos
+		if n.Op != "" {
+			c.emit(OpUpdateMember, c.name(n.Member), c.name(n.Op))
+		} else {
+			c.emit(OpSetMember, c.name(n.Member))
+		}
+		c.emit(OpPopResult)
+
+	case ast.IfNode:
+		c.emit(OpClearResult)
+		c.expression(n.Condition)
+		elseJump := c.emit(OpJumpIfFalse, 0)
+		c.block(n.ThenBody)
+		if len(n.ElseBody) == 0 {
+			c.patch(elseJump, len(c.code.Instructions))
+			return
+		}
+		endJump := c.emit(OpJump, 0)
+		c.patch(elseJump, len(c.code.Instructions))
+		c.block(n.ElseBody)
+		c.patch(endJump, len(c.code.Instructions))
+
+	case ast.WhileNode:
+		c.emit(OpClearResult)
+		start := len(c.code.Instructions)
+		c.expression(n.Condition)
+		exitJump := c.emit(OpJumpIfFalse, 0)
+		jumps := c.loopBody(n.Body)
+		c.emit(OpJump, start)
+		c.patch(exitJump, len(c.code.Instructions))
+		c.patchAll(jumps.breaks, len(c.code.Instructions))
+		c.patchAll(jumps.continues, start)
+
+	case ast.ForNode:
+		if n.Init != nil {
+			c.statement(n.Init)
+		}
+		c.emit(OpClearResult)
+		start := len(c.code.Instructions)
+		exitJump := -1
+		if n.Condition != nil {
+			c.expression(n.Condition)
+			exitJump = c.emit(OpJumpIfFalse, 0)
+		}
+		jumps := c.loopBody(n.Body)
+		c.patchAll(jumps.continues, len(c.code.Instructions))
+		if n.Update != nil {
+			// The update is not part of the body, so it leaves the result alone
+			c.emit(OpSaveResult)
+			c.statement(n.Update)
+			c.emit(OpRestoreResult)
+		}
+		// Without a condition the body runs once, like the tree-walker
+		if n.Condition != nil {
+			c.emit(OpJump, start)
+			c.patch(exitJump, len(c.code.Instructions))
+		}
+		c.patchAll(jumps.breaks, len(c.code.Instructions))
+
+	case ast.ForEachNode:
+		c.emit(OpClearResult)
+		c.expression(n.Iterable)
+		c.pos = n.Pos
+		c.emit(OpIterate, loopVariables(n))
+		// The iterator stays on the stack while the loop runs
+		c.pushUnwind(unwind{kind: unwindTemp})
+		start := len(c.code.Instructions)
+		exitJump := c.emit(OpNext, 0)
+		for _, name := range []string{n.Value, n.Key} {
+			if name != "" {
+				c.emit(OpStore, c.name(name))
+				c.emit(OpPop)
+			}
+		}
+		jumps := c.loopBody(n.Body)
+		c.emit(OpJump, start)
+		c.patch(exitJump, len(c.code.Instructions))
+		c.patchAll(jumps.breaks, len(c.code.Instructions))
+		c.patchAll(jumps.continues, start)
+		c.popUnwind()
+		c.emit(OpPop)
+
+	case ast.MatchNode:
+		c.emit(OpClearResult)
+		c.expression(n.Subject)
+		// The subject stays on the stack while the arms are tried
+		c.pushUnwind(unwind{kind: unwindTemp})
+		var endJumps []int
+		for _, arm := range n.Cases {
+			c.pos = arm.Pos
+			c.code.Arms = append(c.code.Arms, arm.Patterns)
+			c.emit(OpMatch, len(c.code.Arms)-1)
+			nextJump := c.emit(OpJumpIfFalse, 0)
+			c.block(arm.Body)
+			endJumps = append(endJumps, c.emit(OpJump, 0))
+			c.patch(nextJump, len(c.code.Instructions))
+		}
+		c.block(n.Default)
+		c.patchAll(endJumps, len(c.code.Instructions))
+		c.popUnwind()
+		c.emit(OpPop)
+
+	case ast.BreakNode, ast.ContinueNode:
+		depth := c.innermostLoop()
+		c.emit(OpClearResult)
+		c.unwindTo(depth + 1)
+		jump := c.emit(OpJump, 0)
+		loop := c.unwinds[depth].loop
+		if _, isBreak := n.(ast.BreakNode); isBreak {
+			loop.breaks = append(loop.breaks, jump)
+		} else {
+			loop.continues = append(loop.continues, jump)
+		}
+
+	case ast.ReturnNode:
+		if n.Value != nil {
+			c.expression(n.Value)
+		} else {
+			c.emit(OpNil)
+		}
+		// Keep the value in the statement result while finally blocks run
+		c.emit(OpPopResult)
+		c.unwindTo(0)
+		c.pos = n.Pos
+		c.emit(OpSaveResult)
+		c.emit(OpReturn, 1)
+
+	case ast.TryNode:
+		c.tryStatement(n)
+
+	case ast.ThrowNode:
+		c.expression(n.Message)
+		c.pos = n.Pos
+		c.emit(OpThrow)
+
+	case ast.ClassNode, ast.FunctionNode:
+		c.code.Declarations = append(c.code.Declarations, n)
+		c.emit(OpDeclare, len(c.code.Declarations)-1)
+		c.emit(OpClearResult)
+
+	default:
+		c.expression(node)
+		c.emit(OpPopResult)
+	}
+}
+
+// declaration compiles `type name = value`
+func (c *compiler) declaration(name string, value ast.ASTNode) {
+	c.expression(value)
+	c.emit(OpStore, c.name(name))
+	c.emit(OpPopResult)
+}
+
+// tryStatement compiles jaribu/shika/hatimaye. Errors in the try block go to the
+// catch block when it has statements; the finally block runs however the statement
+// is left, and an error that was not caught is thrown again after it.
+func (c *compiler) tryStatement(n ast.TryNode) {
+	hasCatch := len(n.CatchBody) > 0
+	hasFinally := len(n.FinallyBody) > 0
+	if !hasCatch && !hasFinally {
+		c.block(n.TryBody)
+		return
+	}
+
+	if hasFinally {
+		c.pushUnwind(unwind{kind: unwindFinally, finally: n.FinallyBody})
+	}
+	tryHandler := c.emit(OpPushHandler, 0)
+	c.pushUnwind(unwind{kind: unwindHandler})
+	c.block(n.TryBody)
+	c.popUnwind()
+	c.emit(OpPopHandler)
+	var toFinally []int
+	toFinally = append(toFinally, c.emit(OpJump, 0))
+
+	catchHandler := -1
+	if hasCatch {
+		c.patch(tryHandler, len(c.code.Instructions))
+		if hasFinally {
+			catchHandler = c.emit(OpPushHandler, 0)
+			c.pushUnwind(unwind{kind: unwindHandler})
+		}
+		c.emit(OpPushScope)
+		c.pushUnwind(unwind{kind: unwindScope})
+		if n.CatchVar != "" {
+			c.emit(OpCaught)
+			c.emit(OpStore, c.name(n.CatchVar))
+			c.emit(OpPop)
+		}
+		c.block(n.CatchBody)
+		c.popUnwind()
+		c.emit(OpPopScope)
+		if hasFinally {
+			c.popUnwind()
+			c.emit(OpPopHandler)
+		}
+		toFinally = append(toFinally, c.emit(OpJump, 0))
+	}
+
+	if !hasFinally {
+		c.patchAll(toFinally, len(c.code.Instructions))
+		return
+	}
+	c.popUnwind()
+
+	// An error escaped: run the finally block, then throw the error again
+	if hasCatch {
+		c.patch(catchHandler, len(c.code.Instructions))
+	} else {
+		c.patch(tryHandler, len(c.code.Instructions))
+	}
+	c.emit(OpCaught)
+	c.pushUnwind(unwind{kind: unwindTemp})
+	c.block(n.FinallyBody)
+	c.popUnwind()
+	c.emit(OpThrow)
+
+	c.patchAll(toFinally, len(c.code.Instructions))
+	c.finallyBlock(n.FinallyBody)
+}
+
+func (c *compiler) expressions(nodes []ast.ASTNode) {
+	for _, node := range nodes {
+		c.expression(node)
+	}
+}
+
+func (c *compiler) expression(node ast.ASTNode) {
+	c.pos = ast.PosOf(node)
+	switch n := node.(type) {
+	case ast.NumberNode:
+		c.emit(OpConstant, c.constant(numberLiteral(n.Value)))
+
+	case ast.BooleanNode:
+		c.emit(OpConstant, c.constant(BoolValue(n.Value)))
+
+	case ast.StringNode:
+		c.emit(OpConstant, c.constant(StringValue(n.Value)))
+
+	case ast.DictionaryNode:
+		for _, pair := range n.Pairs {
+			c.expression(pair.Key)
+			c.expression(pair.Value)
+		}
+		c.emit(OpDict, len(n.Pairs))
+
+	case ast.ArrayNode:
+		c.expressions(n.Elements)
+		c.emit(OpArray, len(n.Elements))
+
+	case ast.ArrayAccessNode:
+		c.expression(n.Array)
+		c.expression(n.Index)
+		c.pos = n.Pos
+		c.emit(OpIndex)
+
+	case ast.ThisNode:
+		c.emit(OpThis)
+
+	case ast.MemberAccessNode:
+		c.expression(n.Object)
+		c.pos = n.Pos
+		c.emit(OpMember, c.name(n.Member))
+
+	case ast.IdentifierNode:
+		c.emit(OpLoad, c.name(n.Value))
+
+	case ast.BinaryOpNode:
+		c.expression(n.Left)
+		c.expression(n.Right)
+		c.pos = n.Pos
+		c.emit(OpBinary, c.name(n.Op))
+
+	case ast.UnaryOpNode:
+		c.expression(n.Operand)
+		c.pos = n.Pos
+		c.emit(OpUnary, c.name(n.Op))
+
+	case ast.MethodCallNode:
+		c.expression(n.Object)
+		c.pos = n.Pos
+		c.emit(OpGetMethod, c.name(n.Method))
+		c.expressions(n.Args)
+		c.pos = n.Pos
+		c.emit(OpCallMethod, len(n.Args))
+
+	case ast.FunctionCallNode:
+		c.expressions(n.Args)
+		c.pos = n.Pos
+		c.emit(OpCall, c.name(n.Name), len(n.Args))
+
+	case ast.CallNode:
+		c.expression(n.Callee)
+		c.expressions(n.Args)
+		c.pos = n.Pos
+		c.emit(OpCallMethod, len(n.Args))
+
+	case ast.NewInstanceNode:
+		c.emit(OpNew, c.name(n.ClassName))
+		c.expressions(n.Args)
+		c.pos = n.Pos
+		c.emit(OpConstruct, len(n.Args))
+
+	case ast.LambdaNode:
+		c.code.Lambdas = append(c.code.Lambdas, n)
+		c.emit(OpClosure, len(c.code.Lambdas)-1)
+
+	default:
+		c.fail(fmt.Sprintf("Aina ya nodi haijulikani: %T", node), fmt.Sprintf("cannot compile %T", node))
+	}
+}
@@ -1,69 +1,108 @@
 package interpreter
 
 import (
+	"bufio"
+	"cmp"
 	"fmt"
+	"io"
+	"kwenda/ast"
+	"math"
 	"os"
 	"strconv"
 	"strings"
-	"kwenda/ast"
 )
 
-// Special control flow values
-type ControlFlow int
+// control tells the enclosing statements how a statement finished
+type control int
 
 const (
-	ControlNormal ControlFlow = iota
-	ControlBreak
-	ControlContinue
-	ControlReturn
-	ControlThrow
+	controlNormal   control = iota
+	controlBreak            // vunja
+	controlContinue         // endelea
+	controlReturn           // rudisha; the returned value travels alongside
 )
 
-type ControlFlowResult struct {
-	Type  ControlFlow
-	Value interface{}
-}
-
-// ErrorValue represents a runtime error
-type ErrorValue struct {
-	Message  string
-	Context  string // Additional context about where the error occurred
-}
-
 // Environment stores variables and their values
 type Environment struct {
-	Variables map[string]interface{}
+	Variables map[string]Value
 	Functions map[string]ast.FunctionNode
 	Classes   map[string]ast.ClassNode // Class definitions
-	Modules   map[string]*Environment // Module namespaces
-	Parent    *Environment // For function scope
+	Modules   map[string]*ModuleValue  // Module namespaces
+	Streams   *Streams                 // Where the program reads input and writes output
+	Options   *Options                 // How the program runs
+	Parent    *Environment             // For function scope
+}
+
+// Options change how a program runs. Every scope of a program shares one Options.
+type Options struct {
+	// Legacy restores the lenient behaviour of early versions: an undefined name
+	// evaluates to its own name as text, and calling an unknown function prints a
+	// warning and gives tupu instead of raising an error
+	Legacy bool
+}
+
+// Streams are the input and output of a running program. Every scope of a program
+// shares one Streams, so a host can redirect them by replacing its fields.
+type Streams struct {
+	Stdin  io.Reader // Read by ingiza
+	Stdout io.Writer // Written by andika
+	Stderr io.Writer // Unhandled errors and warnings
+
+	lines     *bufio.Reader // Buffers Stdin for ReadLine
+	linesFrom io.Reader     // The Stdin that lines buffers
+}
+
+// ReadLine reads the next line of input without its line ending. It returns
+// false at the end of the input when there is no line left to read. Stdin is
+// buffered across calls, and may be replaced between them.
+func (s *Streams) ReadLine() (string, bool) {
+	if s.lines == nil || s.linesFrom != s.Stdin {
+		// A reader that is already buffered (like the REPL's) is shared as it is
+		if buffered, ok := s.Stdin.(*bufio.Reader); ok {
+			s.lines = buffered
+		} else {
+			s.lines = bufio.NewReader(s.Stdin)
+		}
+		s.linesFrom = s.Stdin
+	}
+	line, err := s.lines.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), true
 }
 
 func NewEnvironment() *Environment {
 	return &Environment{
-		Variables: make(map[string]interface{}),
+		Variables: make(map[string]Value),
 		Functions: make(map[string]ast.FunctionNode),
 		Classes:   make(map[string]ast.ClassNode),
-		Modules:   make(map[string]*Environment),
+		Modules:   make(map[string]*ModuleValue),
+		Streams:   &Streams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr},
+		Options:   &Options{},
 		Parent:    nil,
 	}
 }
 
 func NewChildEnvironment(parent *Environment) *Environment {
 	return &Environment{
-		Variables: make(map[string]interface{}),
+		Variables: make(map[string]Value),
 		Functions: parent.Functions, // Share functions with parent
 		Classes:   parent.Classes,   // Share classes with parent
 		Modules:   parent.Modules,   // Share modules with parent
+		Streams:   parent.Streams,   // Share input and output with parent
+		Options:   parent.Options,
 		Parent:    parent,
 	}
 }
 
-func (env *Environment) Set(name string, value interface{}) {
+func (env *Environment) Set(name string, value Value) {
 	env.Variables[name] = value
 }
 
-func (env *Environment) Get(name string) interface{} {
+// Get looks a variable up through the enclosing scopes; it returns nil when the name is not defined
+func (env *Environment) Get(name string) Value {
 	if value, exists := env.Variables[name]; exists {
 		return value
 	}
@@ -92,1255 +131,1035 @@ func (env *Environment) GetClass(name string) (ast.ClassNode, bool) {
 	return class, exists
 }
 
-// toBool converts a value to boolean following Kwenda's rules
-func toBool(value interface{}) bool {
-	switch v := value.(type) {
-	case bool:
-		return v
-	case int:
-		return v != 0
-	case float64:
-		return v != 0.0
-	case string:
-		return v != ""
-	default:
-		return false
+// Interpret runs a top-level node: declarations are recorded in env, and the main
+// function kuu is executed. Unhandled errors are reported and yield nil.
+func Interpret(node ast.ASTNode, env *Environment) Value {
+	result, err := Run(node, env)
+	if err != nil {
+		ReportError(env.Streams.Stderr, err)
+		return Nil
 	}
+	return result
 }
 
-// toNumber converts a value to float64, returns (value, isFloat)
-func toNumber(value interface{}) (float64, bool) {
-	switch v := value.(type) {
-	case int:
-		return float64(v), false
-	case float64:
-		return v, true
-	case string:
-		if f, err := strconv.ParseFloat(v, 64); err == nil {
-			return f, strings.Contains(v, ".")
-		}
-		return 0, false
-	case bool:
-		if v {
-			return 1, false
-		}
-		return 0, false
-	default:
-		return 0, false
+// Run is like Interpret but returns an unhandled error instead of reporting it
+func Run(node ast.ASTNode, env *Environment) (Value, error) {
+	if function, ok := node.(ast.FunctionNode); ok && function.Name == "kuu" {
+		// Execute main function immediately
+		return runBody(function.Body, env)
+	}
+
+	_, result, err := exec(node, env)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
 }
 
-func Interpret(node ast.ASTNode, env *Environment) interface{} {
-	switch n := node.(type) {
-	case ast.NumberNode:
-		// Try to parse as float first
-		if strings.Contains(n.Value, ".") {
-			value, err := strconv.ParseFloat(n.Value, 64)
-			if err == nil {
-				return value
-			}
-		}
-		// Fall back to integer
-		value, _ := strconv.Atoi(n.Value)
-		return value
+// RunMain runs the body of kuu in env. returned reports whether it ended with
+// rudisha, in which case result is the value it returned.
+func RunMain(main ast.FunctionNode, env *Environment) (result Value, returned bool, err error) {
+	return execBody(main.Body, env)
+}
 
-	case ast.BooleanNode:
-		return n.Value
+// Call calls the lambda, host function, user function or built-in called name
+func Call(name string, args []Value, env *Environment) (Value, error) {
+	result, found, err := callByName(name, args, ast.Pos{}, env, callFunction)
+	if !found {
+		return nil, unknownFunction(name, ast.Pos{}, env)
+	}
+	return result, err
+}
 
-	case ast.StringNode:
-		return n.Value
+// ReportError writes an error that no jaribu block caught to w
+func ReportError(w io.Writer, err error) {
+	e := asErrorValue(err)
+	fmt.Fprintf(w, "\n╔═══════════════════════════════════════════════════════════╗\n")
+	fmt.Fprintf(w, "║ HITILAFU (ERROR)                                          ║\n")
+	fmt.Fprintf(w, "╚═══════════════════════════════════════════════════════════╝\n")
+	fmt.Fprintf(w, "Ujumbe: %s\n", e.Message)
+	if e.Context != "" {
+		fmt.Fprintf(w, "Muktadha: %s\n", e.Context)
+	}
+	if e.Pos.IsValid() {
+		fmt.Fprintf(w, "Mahali: %s\n", e.Pos)
+	}
+	fmt.Fprintf(w, "\n")
+}
 
-	case ast.DictionaryNode:
-		// Handle dictionary literals (e.g., {"key": "value", "age": 25})
-		dict := make(map[string]interface{})
-		for _, pair := range n.Pairs {
-			key := Interpret(pair.Key, env)
-			value := Interpret(pair.Value, env)
-			// Convert key to string
-			keyStr := fmt.Sprintf("%v", key)
-			dict[keyStr] = value
+// thrownError turns the value given to tupa into an error. Re-throwing a caught
+// error keeps its original message and position.
+func thrownError(message Value, pos ast.Pos) error {
+	if e, ok := message.(ErrorValue); ok {
+		return e
+	}
+	return ErrorValue{Message: message.String(), Pos: pos}
+}
+
+// asErrorValue returns the Kwenda error carried by err
+func asErrorValue(err error) ErrorValue {
+	if e, ok := err.(ErrorValue); ok {
+		return e
+	}
+	return ErrorValue{Message: err.Error()}
+}
+
+// execBlock runs statements in order, stopping at the first one that breaks,
+// continues, returns or fails. The value is that of the last statement run.
+func execBlock(statements []ast.ASTNode, env *Environment) (control, Value, error) {
+	var result Value = Nil
+	for _, statement := range statements {
+		flow, value, err := exec(statement, env)
+		if err != nil || flow != controlNormal {
+			return flow, value, err
 		}
-		return dict
+		result = value
+	}
+	return controlNormal, result, nil
+}
 
-	case ast.DictionaryDeclarationNode:
-		// Handle dictionary declarations (e.g., kamusi data = {})
-		dictValue := Interpret(n.Value, env)
-		env.Set(n.Name, dictValue)
-		return dictValue
+// runBody runs a function body and returns its result: the value of rudisha, or
+// else the value of the last statement. vunja and endelea do not escape a function.
+func runBody(body []ast.ASTNode, env *Environment) (Value, error) {
+	result, _, err := execBody(body, env)
+	return result, err
+}
 
-	case ast.ArrayNode:
-		// Handle array literals (e.g., [1, 2, 3])
-		var elements []interface{}
-		for _, element := range n.Elements {
-			value := Interpret(element, env)
-			elements = append(elements, value)
+// execBody is runBody that also reports whether the body ended with rudisha
+func execBody(body []ast.ASTNode, env *Environment) (Value, bool, error) {
+	var result Value = Nil
+	for _, statement := range body {
+		flow, value, err := exec(statement, env)
+		if err != nil {
+			return nil, false, err
+		}
+		if flow == controlReturn {
+			return value, true, nil
 		}
-		return elements
+		if flow != controlNormal {
+			value = Nil
+		}
+		result = value
+	}
+	return result, false, nil
+}
 
-	case ast.ArrayDeclarationNode:
-		// Handle array declarations (e.g., orodha namba x = [1, 2, 3])
-		var elements []interface{}
-		for _, element := range n.Elements {
-			value := Interpret(element, env)
-			elements = append(elements, value)
+// callFunction binds arguments to parameters in a fresh scope and runs the body
+func callFunction(parameters []ast.Parameter, body []ast.ASTNode, args []Value, callEnv *Environment) (Value, error) {
+	for i, param := range parameters {
+		if i < len(args) {
+			callEnv.Set(param.Name, args[i])
 		}
-		env.Set(n.Name, elements)
-		return elements
+	}
+	return runBody(body, callEnv)
+}
 
-	case ast.ArrayAccessNode:
-		// Handle array access (e.g., arr[0]) or dictionary access (e.g., dict["key"])
-		arrayValue := Interpret(n.Array, env)
-		indexValue := Interpret(n.Index, env)
-		
-		// Check if it's a dictionary
-		if dict, ok := arrayValue.(map[string]interface{}); ok {
-			keyStr := fmt.Sprintf("%v", indexValue)
-			if value, exists := dict[keyStr]; exists {
-				return value
-			}
-			return nil
-		}
-		
-		// Otherwise treat as array
-		if arr, ok := arrayValue.([]interface{}); ok {
-			if idx, ok := indexValue.(int); ok {
-				if idx >= 0 && idx < len(arr) {
-					return arr[idx]
-				}
-			}
+// bodyRunner runs a function body with its arguments; each engine provides its own
+type bodyRunner func(parameters []ast.Parameter, body []ast.ASTNode, args []Value, callEnv *Environment) (Value, error)
+
+// callByName calls the lambda, host function, user function or built-in called
+// name, in that order, so the program's own functions hide built-ins with the
+// same name. found is false when nothing by that name can be called.
+func callByName(name string, args []Value, pos ast.Pos, env *Environment, run bodyRunner) (result Value, found bool, err error) {
+	switch callee := env.Get(name).(type) {
+	case *FunctionValue, *HostFunction:
+		result, err = callValue(callee, name, args, pos, env, run)
+		return result, true, err
+	}
+
+	if function, exists := env.GetFunction(name); exists {
+		result, err = run(function.Parameters, function.Body, args, NewChildEnvironment(env))
+		return result, true, err
+	}
+
+	if b, exists := findBuiltin(name); exists {
+		if !b.Accepts(len(args)) {
+			return nil, true, wrongArgumentCount(name, b, len(args), pos)
 		}
-		return nil
+		result, err = b.Call(&BuiltinCall{Name: name, Args: args, Pos: pos, Env: env, run: run})
+		return result, true, err
+	}
+	return nil, false, nil
+}
 
-	case ast.ArrayAssignmentNode:
-		// Handle array assignment (e.g., arr[0] = 5) or dictionary assignment (e.g., dict["key"] = value)
-		arrayValue := Interpret(n.Array, env)
-		indexValue := Interpret(n.Index, env)
-		newValue := Interpret(n.Value, env)
-		
-		// Check if it's a dictionary
-		if dict, ok := arrayValue.(map[string]interface{}); ok {
-			keyStr := fmt.Sprintf("%v", indexValue)
-			dict[keyStr] = newValue
-			return newValue
-		}
-		
-		// Otherwise treat as array
-		if arr, ok := arrayValue.([]interface{}); ok {
-			if idx, ok := indexValue.(int); ok {
-				if idx >= 0 && idx < len(arr) {
-					arr[idx] = newValue
-					return newValue
-				}
-			}
+// callValue calls a function value: a lambda or module function, which runs in a
+// scope inside the one it was created in, or a host function. name is how the
+// caller referred to it, or empty to use the function's own name.
+func callValue(callee Value, name string, args []Value, pos ast.Pos, env *Environment, run bodyRunner) (Value, error) {
+	switch fn := callee.(type) {
+	case *FunctionValue:
+		return run(fn.Parameters, fn.Body, args, NewChildEnvironment(fn.Env))
+	case *HostFunction:
+		if name == "" {
+			name = fn.Name
+		}
+		return fn.Impl(&BuiltinCall{Name: name, Args: args, Pos: pos, Env: env, run: run})
+	}
+	if name == "" {
+		return nil, ErrorValue{
+			Message: fmt.Sprintf("Thamani ya aina %s si kazi, kwa hivyo haiwezi kuitwa", callee.Type()),
+			Context: fmt.Sprintf("A value of type %s is not a function and cannot be called", callee.Type()),
+			Pos:     pos,
 		}
-		return nil
+	}
+	return nil, ErrorValue{
+		Message: fmt.Sprintf("'%s' si kazi; ni thamani ya aina %s", name, callee.Type()),
+		Context: fmt.Sprintf("'%s' is a value of type %s, not a function", name, callee.Type()),
+		Pos:     pos,
+	}
+}
 
-	case ast.StringVariableDeclarationNode:
-		// Handle string variable declarations (e.g., maneno x = "habari")
-		value := Interpret(n.Value, env)
-		env.Set(n.Name, value)
-		return value
+// fieldFunction returns the function stored in an object's field or a dictionary
+// entry called name. Methods of the object's class come first, so it reports
+// false when the class has a method by that name.
+func fieldFunction(object Value, name string, env *Environment) (Value, bool) {
+	if instance, ok := object.(*InstanceValue); ok && findMethodInClass(instance.Class.Definition.Name, name, env) != nil {
+		return nil, false
+	}
+	switch value := getMember(object, name).(type) {
+	case *FunctionValue, *HostFunction:
+		return value, true
+	}
+	return nil, false
+}
 
-	case ast.ThisNode:
-		// Handle 'hii' keyword (this/self)
-		value := env.Get("hii")
-		if value == nil {
-			return ControlFlowResult{Type: ControlThrow, Value: ErrorValue{Message: "'hii' inaweza kutumika tu ndani ya darasa (this can only be used inside a class)"}}
+// memberOf reads object.name; modules report names they do not export
+func memberOf(object Value, name string, pos ast.Pos) (Value, error) {
+	if module, ok := object.(*ModuleValue); ok {
+		return module.Member(name, pos)
+	}
+	return getMember(object, name), nil
+}
+
+// unknownFunction is the error for calling a function that does not exist,
+// suggesting a similar name from env if there is one
+func unknownFunction(name string, pos ast.Pos, env *Environment) error {
+	err := ErrorValue{
+		Message: fmt.Sprintf("Kazi '%s' haijulikani", name),
+		Context: fmt.Sprintf("Unknown function '%s'", name),
+		Pos:     pos,
+	}
+	if env != nil {
+		if suggestion := suggestName(name, functionNames(env)); suggestion != "" {
+			err.Message += fmt.Sprintf("; ulimaanisha '%s'?", suggestion)
+			err.Context += fmt.Sprintf("; did you mean '%s'?", suggestion)
 		}
-		return value
+	}
+	return err
+}
 
-	case ast.MemberAccessNode:
-		// Handle member access (e.g., hii.jina or object.property)
-		objectValue := Interpret(n.Object, env)
-		if dict, ok := objectValue.(map[string]interface{}); ok {
-			if value, exists := dict[n.Member]; exists {
-				return value
-			}
-			return nil
+// missingFunction handles a call to a function that does not exist: an error,
+// or in legacy mode a warning and tupu
+func missingFunction(name string, pos ast.Pos, env *Environment) (Value, error) {
+	if env.Options.Legacy {
+		fmt.Fprintf(env.Streams.Stderr, "Kazi '%s' haijulikani\n", name)
+		return Nil, nil
+	}
+	return nil, unknownFunction(name, pos, env)
+}
+
+// exec runs a statement. Expressions used as statements are evaluated for their value.
+func exec(node ast.ASTNode, env *Environment) (control, Value, error) {
+	switch n := node.(type) {
+	case ast.VariableDeclarationNode:
+		// Handle variable declarations (e.g., namba x = 10)
+		value, err := eval(n.Value, env)
+		if err != nil {
+			return controlNormal, nil, err
 		}
-		return nil
+		env.Set(n.Name, value)
+		return controlNormal, value, nil
 
-	case ast.MemberAssignmentNode:
-		// Handle member assignment (e.g., hii.jina = "Amina")
-		objectValue := Interpret(n.Object, env)
-		newValue := Interpret(n.Value, env)
-		if dict, ok := objectValue.(map[string]interface{}); ok {
-			dict[n.Member] = newValue
-			return newValue
+	case ast.StringVariableDeclarationNode:
+		// Handle string variable declarations (e.g., maneno x = "habari")
+		value, err := eval(n.Value, env)
+		if err != nil {
+			return controlNormal, nil, err
 		}
-		return nil
+		env.Set(n.Name, value)
+		return controlNormal, value, nil
 
-	case ast.IdentifierNode:
-		// Check if it's a string literal (starts and ends with quotes)
-		if len(n.Value) >= 2 && n.Value[0] == '"' && n.Value[len(n.Value)-1] == '"' {
-			return n.Value[1 : len(n.Value)-1] // Remove quotes
-		}
-		
-		// Check if it's a module access (e.g., math.PI or math.ongeza_kubwa)
-		if strings.Contains(n.Value, ".") {
-			parts := strings.SplitN(n.Value, ".", 2)
-			moduleName := parts[0]
-			memberName := parts[1]
-			
-			if moduleEnv, exists := env.Modules[moduleName]; exists {
-				// Try to get variable first
-				if value := moduleEnv.Get(memberName); value != nil {
-					return value
-				}
-				// Try to get function
-				if function, exists := moduleEnv.GetFunction(memberName); exists {
-					// Return a callable reference (we'll handle this in FunctionCallNode)
-					return function
-				}
-			}
-			// Module or member not found, return as-is for debugging
-			return n.Value
+	case ast.ClassVariableDeclarationNode:
+		// Handle class-typed variable declarations (e.g., Mtu mtu1 = unda Mtu("Amina"))
+		value, err := eval(n.Value, env)
+		if err != nil {
+			return controlNormal, nil, err
 		}
-		
-		// Look up the identifier in the environment
-		value := env.Get(n.Value)
-		if value == nil {
-			// If not found in environment, return the identifier name itself (for debugging)
-			return n.Value
+		env.Set(n.VarName, value)
+		return controlNormal, value, nil
+
+	case ast.DictionaryDeclarationNode:
+		// Handle dictionary declarations (e.g., kamusi data = {})
+		value, err := eval(n.Value, env)
+		if err != nil {
+			return controlNormal, nil, err
 		}
-		return value
+		env.Set(n.Name, value)
+		return controlNormal, value, nil
 
-	case ast.BinaryOpNode:
-		left := Interpret(n.Left, env)
-		right := Interpret(n.Right, env)
-		
-		// Handle logical operators first
-		if n.Op == "na" || n.Op == "au" {
-			// Convert to boolean
-			leftBool := toBool(left)
-			rightBool := toBool(right)
-			
-			switch n.Op {
-			case "na": // AND
-				return leftBool && rightBool
-			case "au": // OR
-				return leftBool || rightBool
+	case ast.ArrayDeclarationNode:
+		// Handle array declarations (e.g., orodha namba x = [1, 2, 3])
+		var value Value
+		if n.Value != nil {
+			// Initialised from an expression (e.g., orodha namba x = tengeneza())
+			result, err := eval(n.Value, env)
+			if err != nil {
+				return controlNormal, nil, err
 			}
-		}
-		
-		// Handle comparison operators that can work with booleans
-		if n.Op == "==" || n.Op == "!=" {
-			// If both are booleans, compare as booleans
-			if leftBool, leftIsBool := left.(bool); leftIsBool {
-				if rightBool, rightIsBool := right.(bool); rightIsBool {
-					if n.Op == "==" {
-						return leftBool == rightBool
-					} else {
-						return leftBool != rightBool
-					}
-				}
+			value = result
+		} else {
+			elements, err := evalArgs(n.Elements, env)
+			if err != nil {
+				return controlNormal, nil, err
 			}
+			value = NewArray(elements)
 		}
-		
-		// Convert to numeric values (int or float64)
-		leftFloat, leftIsFloat := toNumber(left)
-		rightFloat, rightIsFloat := toNumber(right)
-		
-		// If either is float, use float arithmetic
-		useFloat := leftIsFloat || rightIsFloat
-		
-		switch n.Op {
-		case "+":
-			// Handle string concatenation
-			if leftStr, leftIsStr := left.(string); leftIsStr {
-				if rightStr, rightIsStr := right.(string); rightIsStr {
-					return leftStr + rightStr
-				}
-				// Convert right to string and concatenate
-				return leftStr + fmt.Sprintf("%v", right)
-			}
-			if rightStr, rightIsStr := right.(string); rightIsStr {
-				// Convert left to string and concatenate
-				return fmt.Sprintf("%v", left) + rightStr
-			}
-			// Numeric addition
-			if useFloat {
-				return leftFloat + rightFloat
-			}
-			return int(leftFloat) + int(rightFloat)
-		case "-":
-			if useFloat {
-				return leftFloat - rightFloat
-			}
-			return int(leftFloat) - int(rightFloat)
-		case "*":
-			if useFloat {
-				return leftFloat * rightFloat
-			}
-			return int(leftFloat) * int(rightFloat)
-		case "/":
-			if rightFloat != 0 {
-				// Division always returns float if either operand is float
-				if useFloat {
-					return leftFloat / rightFloat
-				}
-				// Integer division
-				return int(leftFloat) / int(rightFloat)
-			}
-			return 0
-		case "==":
-			// Handle string comparison
-			if leftStr, leftIsStr := left.(string); leftIsStr {
-				if rightStr, rightIsStr := right.(string); rightIsStr {
-					return leftStr == rightStr
-				}
-			}
-			return leftFloat == rightFloat
-		case "!=":
-			// Handle string comparison
-			if leftStr, leftIsStr := left.(string); leftIsStr {
-				if rightStr, rightIsStr := right.(string); rightIsStr {
-					return leftStr != rightStr
-				}
+		env.Set(n.Name, value)
+		return controlNormal, value, nil
+
+	case ast.ArrayAssignmentNode:
+		// Handle array assignment (e.g., arr[0] = 5) or dictionary assignment (e.g., dict["key"] = value)
+		container, err := eval(n.Array, env)
+		if err != nil {
+			return controlNormal, nil, err
+		}
+		index, err := eval(n.Index, env)
+		if err != nil {
+			return controlNormal, nil, err
+		}
+		newValue, err := eval(n.Value, env)
+		if err != nil {
+			return controlNormal, nil, err
+		}
+		if n.Op != "" {
+			// Compound assignment (e.g., arr[0] += 5) reads the element once, after the value
+			if newValue, err = binaryOp(n.Op, getIndex(container, index), newValue, n.Pos); err != nil {
+				return controlNormal, nil, err
 			}
-			return leftFloat != rightFloat
-		case "<":
-			return leftFloat < rightFloat
-		case "<=":
-			return leftFloat <= rightFloat
-		case ">":
-			return leftFloat > rightFloat
-		case ">=":
-			return leftFloat >= rightFloat
-		case "=":
-			// This should not happen in binary operations - assignment is handled in VariableDeclarationNode
-			fmt.Println("Operesheni ya assignment haiwezi kuwa katika binary operation")
-			return nil
-		default:
-			fmt.Println("Operesheni isiyojulikana:", n.Op)
-			return nil
 		}
 
-	case ast.ReturnNode:
-		if n.Value != nil {
-			value := Interpret(n.Value, env)
-			return ControlFlowResult{Type: ControlReturn, Value: value}
-		}
-		return ControlFlowResult{Type: ControlReturn, Value: nil}
+		return controlNormal, setIndex(container, index, newValue), nil
 
-	case ast.InputNode:
-		if n.Prompt != "" {
-			fmt.Print(n.Prompt + " ")
-		} else {
-			fmt.Print("Ingiza thamani: ")
+	case ast.MemberAssignmentNode:
+		// Handle member assignment (e.g., hii.jina = "Amina")
+		object, err := eval(n.Object, env)
+		if err != nil {
+			return controlNormal, nil, err
+		}
+		newValue, err := eval(n.Value, env)
+		if err != nil {
+			return controlNormal, nil, err
+		}
+		if n.Op != "" {
+			// Compound assignment (e.g., hii.umri += 1)
+			if newValue, err = binaryOp(n.Op, getMember(object, n.Member), newValue, n.Pos); err != nil {
+				return controlNormal, nil, err
+			}
 		}
-		
-		var input string
-		fmt.Scanln(&input)
+		return controlNormal, setMember(object, n.Member, newValue), nil
 
-		// Always try to convert the input to a number for namba variables
-		if num, err := strconv.Atoi(input); err == nil {
-			return num
+	case ast.IfNode:
+		// Handle conditional statements (kama ... { ... } sivyo { ... })
+		condition, err := eval(n.Condition, env)
+		if err != nil {
+			return controlNormal, nil, err
 		}
-		// If conversion fails, return 0 for numeric operations
-		return 0
-
-	case ast.MethodCallNode:
-		// Handle method calls with dot notation (e.g., object.method(args))
-		objectValue := Interpret(n.Object, env)
-		
-		// Get the object's class type
-		if dict, ok := objectValue.(map[string]interface{}); ok {
-			// Check if this is a class instance with a __class__ field
-			if className, hasClass := dict["__class__"].(string); hasClass {
-				// Find the method in the class or its parent chain
-				method := findMethodInClass(className, n.Method, env)
-				if method != nil {
-					// Create new environment for method execution
-					methodEnv := &Environment{
-						Variables: make(map[string]interface{}),
-						Functions: env.Functions,
-						Classes:   env.Classes,
-						Modules:   env.Modules,
-						Parent:    env,
-					}
-					
-					// Set 'hii' to refer to the current instance
-					methodEnv.Set("hii", objectValue)
-					
-					// Bind parameters
-					for i, param := range method.Parameters {
-						if i < len(n.Args) {
-							argValue := Interpret(n.Args[i], env)
-							methodEnv.Set(param.Name, argValue)
-						}
-					}
-					
-					// Execute method body
-					var result interface{}
-					for _, stmt := range method.Body {
-						result = Interpret(stmt, methodEnv)
-						
-						// Handle control flow
-						if cf, ok := result.(ControlFlowResult); ok {
-							if cf.Type == ControlReturn {
-								return cf.Value
-							}
-							if cf.Type == ControlThrow {
-								return cf
-							}
-						}
-					}
-					return result
-				}
-				
-				// Method not found
-				return ControlFlowResult{
-					Type: ControlThrow,
-					Value: ErrorValue{
-						Message: fmt.Sprintf("Mbinu '%s' haipatikani katika darasa '%s'", n.Method, className),
-						Context: fmt.Sprintf("Method '%s' not found in class '%s'", n.Method, className),
-					},
-				}
-			}
+		if toBool(condition) {
+			return execBlock(n.ThenBody, env)
 		}
-		
-		// If not a class instance, return error
-		return ControlFlowResult{
-			Type: ControlThrow,
-			Value: ErrorValue{
-				Message: "Haiwezi kuita mbinu kwenye kitu ambacho si instance ya darasa",
-				Context: "Cannot call method on non-class instance",
-			},
+		if len(n.ElseBody) > 0 {
+			return execBlock(n.ElseBody, env)
 		}
+		return controlNormal, Nil, nil
 
-	case ast.FunctionCallNode:
-		// Handle built-in function calls
-		if n.Name == "andika" {
-			for i, arg := range n.Args {
-				result := Interpret(arg, env)
-				if i > 0 {
-					fmt.Print(" ")
-				}
-				// Special formatting for dictionaries
-				if dict, ok := result.(map[string]interface{}); ok {
-					fmt.Print("{")
-					first := true
-					for key, value := range dict {
-						if !first {
-							fmt.Print(", ")
-						}
-						fmt.Printf("%q: %v", key, value)
-						first = false
-					}
-					fmt.Print("}")
-				} else if arr, ok := result.([]interface{}); ok {
-					// Special formatting for arrays
-					fmt.Print("[")
-					for j, elem := range arr {
-						if j > 0 {
-							fmt.Print(", ")
-						}
-						fmt.Print(elem)
-					}
-					fmt.Print("]")
-				} else {
-					fmt.Print(result)
-				}
+	case ast.WhileNode:
+		// Handle while loops (wakati condition { ... })
+		var result Value = Nil
+		for {
+			condition, err := eval(n.Condition, env)
+			if err != nil {
+				return controlNormal, nil, err
 			}
-			fmt.Println()
-			return nil
-		}
-
-		// Array manipulation functions
-		if n.Name == "ongeza" && len(n.Args) == 2 {
-			// Add element to array: ongeza(array, element)
-			element := Interpret(n.Args[1], env)
-			
-			// Update the original array variable if it's an identifier
-			if arrayNode, ok := n.Args[0].(ast.IdentifierNode); ok {
-				arrayArg := env.Get(arrayNode.Value)
-				if arr, ok := arrayArg.([]interface{}); ok {
-					newArr := append(arr, element)
-					env.Set(arrayNode.Value, newArr)
-					return len(newArr) // Return new length
-				}
+			if !toBool(condition) {
+				break
 			}
-			return 0
-		}
-
-		if n.Name == "ondoa" && len(n.Args) == 2 {
-			// Remove element at index: ondoa(array, index)
-			indexArg := Interpret(n.Args[1], env)
-			
-			// Update the original array variable if it's an identifier
-			if arrayNode, ok := n.Args[0].(ast.IdentifierNode); ok {
-				arrayArg := env.Get(arrayNode.Value)
-				if arr, ok := arrayArg.([]interface{}); ok {
-					if idx, ok := indexArg.(int); ok {
-						if idx >= 0 && idx < len(arr) {
-							// Remove element at index
-							newArr := append(arr[:idx], arr[idx+1:]...)
-							env.Set(arrayNode.Value, newArr)
-							return len(newArr) // Return new length
-						}
-					}
-				}
+
+			flow, value, err := execBlock(n.Body, env)
+			if err != nil || flow == controlReturn {
+				return flow, value, err
 			}
-			return 0
+			if flow == controlBreak {
+				break
+			}
+			result = value
 		}
+		return controlNormal, result, nil
 
-		if n.Name == "urefu_orodha" && len(n.Args) == 1 {
-			// Get array length: urefu_orodha(array)
-			arrayArg := Interpret(n.Args[0], env)
-			if arr, ok := arrayArg.([]interface{}); ok {
-				return len(arr)
-			}
-			return 0
-		}
-
-		if n.Name == "pata" && len(n.Args) == 2 {
-			// Get element at index: pata(array, index)
-			arrayArg := Interpret(n.Args[0], env)
-			indexArg := Interpret(n.Args[1], env)
-			
-			if arr, ok := arrayArg.([]interface{}); ok {
-				if idx, ok := indexArg.(int); ok {
-					if idx >= 0 && idx < len(arr) {
-						return arr[idx]
-					} else {
-						// Throw error for invalid index
-						errorMsg := fmt.Sprintf("Index %d ni nje ya mipaka ya orodha (urefu: %d)", idx, len(arr))
-						context := fmt.Sprintf("Katika kazi 'pata': Jaribu kutumia index kati ya 0 na %d", len(arr)-1)
-						return ControlFlowResult{Type: ControlThrow, Value: ErrorValue{Message: errorMsg, Context: context}}
-					}
-				} else {
-					return ControlFlowResult{Type: ControlThrow, Value: ErrorValue{Message: "Index lazima iwe namba", Context: "Katika kazi 'pata'"}}
-				}
+	case ast.ForNode:
+		// Handle for loops (kwa init; condition; update { ... })
+		var result Value = Nil
+
+		// Execute initialization if present
+		if n.Init != nil {
+			if _, _, err := exec(n.Init, env); err != nil {
+				return controlNormal, nil, err
 			}
-			return ControlFlowResult{Type: ControlThrow, Value: ErrorValue{Message: "Hii si orodha", Context: "Katika kazi 'pata': Argument ya kwanza lazima iwe orodha"}}
 		}
 
-		// File I/O operations
-		if n.Name == "soma" && len(n.Args) == 1 {
-			// Read file: soma("filename.txt")
-			filenameArg := Interpret(n.Args[0], env)
-			if filename, ok := filenameArg.(string); ok {
-				content, err := os.ReadFile(filename)
+		for {
+			// Check condition if present
+			if n.Condition != nil {
+				condition, err := eval(n.Condition, env)
 				if err != nil {
-					// Throw an error instead of just printing
-					errorMsg := fmt.Sprintf("Hitilafu ya kusoma faili '%s': %v", filename, err)
-					context := "Katika kazi 'soma': Hakikisha faili ipo na una ruhusa ya kusoma"
-					return ControlFlowResult{Type: ControlThrow, Value: ErrorValue{Message: errorMsg, Context: context}}
-				}
-				return string(content)
-			}
-			context := "Katika kazi 'soma': Argument lazima iwe jina la faili (maneno)"
-			return ControlFlowResult{Type: ControlThrow, Value: ErrorValue{Message: "Jina la faili si sahihi", Context: context}}
-		}
-
-		if n.Name == "andika_faili" && len(n.Args) >= 2 {
-			// Write to file: andika_faili("filename.txt", "content") or andika_faili("filename.txt", "content", kweli) for append
-			filenameArg := Interpret(n.Args[0], env)
-			contentArg := Interpret(n.Args[1], env)
-			
-			if filename, ok := filenameArg.(string); ok {
-				// Convert content to string if it's not already
-				var content string
-				if str, ok := contentArg.(string); ok {
-					content = str
-				} else {
-					content = fmt.Sprintf("%v", contentArg)
+					return controlNormal, nil, err
 				}
-				
-				// Check if append mode is specified
-				append := false
-				if len(n.Args) >= 3 {
-					appendArg := Interpret(n.Args[2], env)
-					if appendVal, ok := appendArg.(bool); ok {
-						append = appendVal
-					}
-				}
-				
-				var err error
-				if append {
-					// Append to file
-					file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-					if err != nil {
-						fmt.Printf("Hitilafu ya kufungua faili '%s': %v\n", filename, err)
-						return false
-					}
-					defer file.Close()
-					
-					_, err = file.WriteString(content)
-				} else {
-					// Overwrite file
-					err = os.WriteFile(filename, []byte(content), 0644)
-				}
-				
-				if err != nil {
-					fmt.Printf("Hitilafu ya kuandika faili '%s': %v\n", filename, err)
-					return false
+				if !toBool(condition) {
+					break
 				}
-				return true
 			}
-			return false
-		}
 
-		if n.Name == "unda_faili" && len(n.Args) == 1 {
-			// Create empty file: unda_faili("filename.txt")
-			filenameArg := Interpret(n.Args[0], env)
-			if filename, ok := filenameArg.(string); ok {
-				file, err := os.Create(filename)
-				if err != nil {
-					fmt.Printf("Hitilafu ya kuunda faili '%s': %v\n", filename, err)
-					return false
+			flow, value, err := execBlock(n.Body, env)
+			if err != nil || flow == controlReturn {
+				return flow, value, err
+			}
+			if flow == controlBreak {
+				break
+			}
+			result = value
+
+			// Execute update if present (also after endelea)
+			if n.Update != nil {
+				if _, _, err := exec(n.Update, env); err != nil {
+					return controlNormal, nil, err
 				}
-				file.Close()
-				return true
 			}
-			return false
-		}
 
-		if n.Name == "faili_ipo" && len(n.Args) == 1 {
-			// Check if file exists: faili_ipo("filename.txt")
-			filenameArg := Interpret(n.Args[0], env)
-			if filename, ok := filenameArg.(string); ok {
-				_, err := os.Stat(filename)
-				return err == nil
+			// If no condition, break after first iteration to prevent infinite loop
+			if n.Condition == nil {
+				break
 			}
-			return false
 		}
+		return controlNormal, result, nil
 
-		if n.Name == "ondoa_faili" && len(n.Args) == 1 {
-			// Delete file: ondoa_faili("filename.txt")
-			filenameArg := Interpret(n.Args[0], env)
-			if filename, ok := filenameArg.(string); ok {
-				err := os.Remove(filename)
-				if err != nil {
-					fmt.Printf("Hitilafu ya kuondoa faili '%s': %v\n", filename, err)
-					return false
-				}
-				return true
-			}
-			return false
+	case ast.ForEachNode:
+		// Handle for-each loops (kwa kila x katika orodha { ... })
+		iterable, err := eval(n.Iterable, env)
+		if err != nil {
+			return controlNormal, nil, err
+		}
+		it, err := iterate(iterable, loopVariables(n), n.Pos)
+		if err != nil {
+			return controlNormal, nil, err
 		}
 
-		// String manipulation functions
-		if n.Name == "urefu" && len(n.Args) == 1 {
-			// Get string length
-			arg := Interpret(n.Args[0], env)
-			if str, ok := arg.(string); ok {
-				return len(str)
+		var result Value = Nil
+		for {
+			key, value, ok := it.step()
+			if !ok {
+				break
 			}
-			return 0
-		}
-
-		if n.Name == "unganisha" && len(n.Args) >= 2 {
-			// Concatenate strings
-			var result strings.Builder
-			for _, arg := range n.Args {
-				value := Interpret(arg, env)
-				if str, ok := value.(string); ok {
-					result.WriteString(str)
-				} else {
-					result.WriteString(fmt.Sprintf("%v", value))
-				}
+			if n.Key != "" {
+				env.Set(n.Key, key)
 			}
-			return result.String()
-		}
-
-		if n.Name == "kata" && len(n.Args) >= 2 {
-			// Substring function: kata(string, start) or kata(string, start, length)
-			str := Interpret(n.Args[0], env)
-			start := Interpret(n.Args[1], env)
-			
-			if strVal, ok := str.(string); ok {
-				if startVal, ok := start.(int); ok {
-					if startVal < 0 || startVal >= len(strVal) {
-						return ""
-					}
-					
-					if len(n.Args) == 3 {
-						// kata(string, start, length)
-						length := Interpret(n.Args[2], env)
-						if lengthVal, ok := length.(int); ok {
-							end := startVal + lengthVal
-							if end > len(strVal) {
-								end = len(strVal)
-							}
-							return strVal[startVal:end]
-						}
-					} else {
-						// kata(string, start) - from start to end
-						return strVal[startVal:]
-					}
-				}
+			env.Set(n.Value, value)
+
+			flow, value, err := execBlock(n.Body, env)
+			if err != nil || flow == controlReturn {
+				return flow, value, err
 			}
-			return ""
-		}
-
-		if n.Name == "badilisha" && len(n.Args) == 3 {
-			// Replace function: badilisha(string, old, new)
-			str := Interpret(n.Args[0], env)
-			old := Interpret(n.Args[1], env)
-			new := Interpret(n.Args[2], env)
-			
-			if strVal, ok := str.(string); ok {
-				if oldVal, ok := old.(string); ok {
-					if newVal, ok := new.(string); ok {
-						return strings.ReplaceAll(strVal, oldVal, newVal)
-					}
-				}
+			if flow == controlBreak {
+				break
 			}
-			return str
+			result = value
 		}
+		return controlNormal, result, nil
 
-		if n.Name == "tafuta" && len(n.Args) == 2 {
-			// Find function: tafuta(string, substring) - returns index or -1
-			str := Interpret(n.Args[0], env)
-			substr := Interpret(n.Args[1], env)
-			
-			if strVal, ok := str.(string); ok {
-				if substrVal, ok := substr.(string); ok {
-					return strings.Index(strVal, substrVal)
-				}
-			}
-			return -1
+	case ast.MatchNode:
+		// Handle pattern matching (chagua x { wakati ... { ... } sivyo { ... } })
+		subject, err := eval(n.Subject, env)
+		if err != nil {
+			return controlNormal, nil, err
 		}
-
-		if n.Name == "awali" && len(n.Args) == 2 {
-			// Starts with function: awali(string, prefix) - returns boolean
-			str := Interpret(n.Args[0], env)
-			prefix := Interpret(n.Args[1], env)
-			
-			if strVal, ok := str.(string); ok {
-				if prefixVal, ok := prefix.(string); ok {
-					return strings.HasPrefix(strVal, prefixVal)
-				}
+		for _, arm := range n.Cases {
+			matched, err := matchArm(arm.Patterns, subject, env)
+			if err != nil {
+				return controlNormal, nil, err
 			}
-			return false
-		}
-
-		if n.Name == "mwisho" && len(n.Args) == 2 {
-			// Ends with function: mwisho(string, suffix) - returns boolean
-			str := Interpret(n.Args[0], env)
-			suffix := Interpret(n.Args[1], env)
-			
-			if strVal, ok := str.(string); ok {
-				if suffixVal, ok := suffix.(string); ok {
-					return strings.HasSuffix(strVal, suffixVal)
-				}
+			if matched {
+				return execBlock(arm.Body, env)
 			}
-			return false
 		}
+		if n.HasDefault {
+			return execBlock(n.Default, env)
+		}
+		return controlNormal, Nil, nil
 
-		if n.Name == "herufi_kubwa" && len(n.Args) == 1 {
-			// Convert to uppercase: herufi_kubwa(string)
-			str := Interpret(n.Args[0], env)
-			if strVal, ok := str.(string); ok {
-				return strings.ToUpper(strVal)
-			}
-			return str
+	case ast.BreakNode:
+		// Handle break statements (vunja)
+		return controlBreak, Nil, nil
+
+	case ast.ContinueNode:
+		// Handle continue statements (endelea)
+		return controlContinue, Nil, nil
+
+	case ast.ReturnNode:
+		if n.Value == nil {
+			return controlReturn, Nil, nil
 		}
+		value, err := eval(n.Value, env)
+		if err != nil {
+			return controlNormal, nil, err
+		}
+		return controlReturn, value, nil
 
-		if n.Name == "herufi_ndogo" && len(n.Args) == 1 {
-			// Convert to lowercase: herufi_ndogo(string)
-			str := Interpret(n.Args[0], env)
-			if strVal, ok := str.(string); ok {
-				return strings.ToLower(strVal)
+	case ast.TryNode:
+		// Handle try-catch blocks (jaribu ... shika ... hatimaye ...)
+		flow, result, err := execBlock(n.TryBody, env)
+
+		// If an error was thrown, execute catch block with the error bound
+		if err != nil && len(n.CatchBody) > 0 {
+			catchEnv := NewChildEnvironment(env)
+			if n.CatchVar != "" {
+				catchEnv.Set(n.CatchVar, asErrorValue(err))
 			}
-			return str
+			flow, result, err = execBlock(n.CatchBody, catchEnv)
 		}
 
-		if n.Name == "ondoa_nafasi" && len(n.Args) == 1 {
-			// Trim whitespace: ondoa_nafasi(string)
-			str := Interpret(n.Args[0], env)
-			if strVal, ok := str.(string); ok {
-				return strings.TrimSpace(strVal)
-			}
-			return str
-		}
-
-		if n.Name == "gawanya_maneno" && len(n.Args) >= 1 {
-			// Split string: gawanya_maneno(string) or gawanya_maneno(string, separator)
-			str := Interpret(n.Args[0], env)
-			if strVal, ok := str.(string); ok {
-				if len(n.Args) == 2 {
-					separator := Interpret(n.Args[1], env)
-					if sepVal, ok := separator.(string); ok {
-						parts := strings.Split(strVal, sepVal)
-						// Return the number of parts for now (could be enhanced to return array)
-						return len(parts)
-					}
-				} else {
-					// Split by whitespace
-					parts := strings.Fields(strVal)
-					return len(parts)
-				}
+		// Execute finally block if present; it runs even when leaving by rudisha or an error
+		if len(n.FinallyBody) > 0 {
+			finallyFlow, finallyResult, finallyErr := execBlock(n.FinallyBody, env)
+			if finallyErr != nil {
+				return controlNormal, nil, finallyErr
 			}
-			return 0
-		}
-
-		// Check if it's a module function call (e.g., math.ongeza_kubwa)
-		if strings.Contains(n.Name, ".") {
-			parts := strings.SplitN(n.Name, ".", 2)
-			moduleName := parts[0]
-			functionName := parts[1]
-			
-			if moduleEnv, exists := env.Modules[moduleName]; exists {
-				if function, exists := moduleEnv.GetFunction(functionName); exists {
-					// Create new environment for function execution
-					funcEnv := NewChildEnvironment(moduleEnv)
-
-					// Evaluate arguments and bind to parameters
-					for i, param := range function.Parameters {
-						if i < len(n.Args) {
-							argValue := Interpret(n.Args[i], env)
-							funcEnv.Set(param.Name, argValue)
-						}
-					}
-
-					// Execute function body
-					var result interface{}
-					for _, statement := range function.Body {
-						result = Interpret(statement, funcEnv)
-
-						// Check for return statement or throw
-						if cf, ok := result.(ControlFlowResult); ok {
-							if cf.Type == ControlReturn {
-								return cf.Value
-							} else if cf.Type == ControlThrow {
-								// Propagate throw from module function
-								return cf
-							}
-							// Other control flow (break/continue) should not escape function
-						}
-					}
-
-					return result
-				}
+			// Finally block can override return values
+			if finallyFlow != controlNormal {
+				return finallyFlow, finallyResult, nil
 			}
 		}
+		return flow, result, err
 
-		// Check if it's a lambda stored in a variable
-		if lambdaValue := env.Get(n.Name); lambdaValue != nil {
-			if lambda, ok := lambdaValue.(map[string]interface{}); ok {
-				if lambdaType, hasType := lambda["__type__"].(string); hasType && lambdaType == "lambda" {
-					// Call the lambda
-					parameters := lambda["__parameters__"].([]ast.Parameter)
-					body := lambda["__body__"].([]ast.ASTNode)
-					closureEnv := lambda["__env__"].(*Environment)
-					
-					// Create new environment for lambda execution (with closure)
-					lambdaEnv := NewChildEnvironment(closureEnv)
-					
-					// Evaluate arguments and bind to parameters
-					for i, param := range parameters {
-						if i < len(n.Args) {
-							argValue := Interpret(n.Args[i], env)
-							lambdaEnv.Set(param.Name, argValue)
-						}
-					}
-					
-					// Execute lambda body
-					var result interface{}
-					for _, statement := range body {
-						result = Interpret(statement, lambdaEnv)
-						
-						// Check for return statement or throw
-						if cf, ok := result.(ControlFlowResult); ok {
-							if cf.Type == ControlReturn {
-								return cf.Value
-							} else if cf.Type == ControlThrow {
-								return cf
-							}
-						}
-					}
-					
-					return result
-				}
-			}
+	case ast.ThrowNode:
+		// Handle throw statements (tupa)
+		message, err := eval(n.Message, env)
+		if err != nil {
+			return controlNormal, nil, err
 		}
+		return controlNormal, nil, thrownError(message, n.Pos)
 
-		// Handle user-defined function calls
-		if function, exists := env.GetFunction(n.Name); exists {
-			// Create new environment for function execution
-			funcEnv := NewChildEnvironment(env)
+	case ast.ClassNode:
+		// Store class definition in environment
+		env.SetClass(n.Name, n)
+		return controlNormal, Nil, nil
 
-			// Evaluate arguments and bind to parameters
-			for i, param := range function.Parameters {
-				if i < len(n.Args) {
-					argValue := Interpret(n.Args[i], env)
-					funcEnv.Set(param.Name, argValue)
-				}
-			}
+	case ast.FunctionNode:
+		// Store user-defined function
+		env.SetFunction(n.Name, n)
+		return controlNormal, Nil, nil
 
-			// Execute function body
-			var result interface{}
-			for _, statement := range function.Body {
-				result = Interpret(statement, funcEnv)
-
-				// Check for return statement or throw
-				if cf, ok := result.(ControlFlowResult); ok {
-					if cf.Type == ControlReturn {
-						return cf.Value
-					} else if cf.Type == ControlThrow {
-						// Propagate throw from user-defined function
-						return cf
-					}
-					// Other control flow (break/continue) should not escape function
-				}
-			}
+	default:
+		value, err := eval(node, env)
+		return controlNormal, value, err
+	}
+}
 
-			return result
+// evalArgs evaluates expressions left to right
+func evalArgs(nodes []ast.ASTNode, env *Environment) ([]Value, error) {
+	values := make([]Value, 0, len(nodes))
+	for _, node := range nodes {
+		value, err := eval(node, env)
+		if err != nil {
+			return nil, err
 		}
+		values = append(values, value)
+	}
+	return values, nil
+}
 
-		fmt.Printf("Kazi '%s' haijulikani\n", n.Name)
-		return nil
+// eval evaluates an expression. Errors raised by tupa or by the runtime are returned as ErrorValue.
+func eval(node ast.ASTNode, env *Environment) (Value, error) {
+	switch n := node.(type) {
+	case ast.NumberNode:
+		return numberLiteral(n.Value), nil
 
-	case ast.VariableDeclarationNode:
-		// Handle variable declarations (e.g., namba x = 10)
-		value := Interpret(n.Value, env)
-		env.Set(n.Name, value)
-		return value
+	case ast.BooleanNode:
+		return BoolValue(n.Value), nil
 
-	case ast.IfNode:
-		// Handle conditional statements (kama ... { ... } sivyo { ... })
-		condition := Interpret(n.Condition, env)
-		
-		// Convert condition to boolean
-		conditionBool := toBool(condition)
-		
-		if conditionBool {
-			// Execute then body
-			var result interface{}
-			for _, statement := range n.ThenBody {
-				result = Interpret(statement, env)
-				// Propagate return statements
-				if cf, ok := result.(ControlFlowResult); ok && cf.Type == ControlReturn {
-					return result
-				}
+	case ast.StringNode:
+		return StringValue(n.Value), nil
+
+	case ast.DictionaryNode:
+		// Handle dictionary literals (e.g., {"key": "value", "age": 25})
+		dict := NewDict()
+		for _, pair := range n.Pairs {
+			key, err := eval(pair.Key, env)
+			if err != nil {
+				return nil, err
 			}
-			return result
-		} else if len(n.ElseBody) > 0 {
-			// Execute else body
-			var result interface{}
-			for _, statement := range n.ElseBody {
-				result = Interpret(statement, env)
-				// Propagate return statements
-				if cf, ok := result.(ControlFlowResult); ok && cf.Type == ControlReturn {
-					return result
-				}
+			value, err := eval(pair.Value, env)
+			if err != nil {
+				return nil, err
 			}
-			return result
+			dict.Set(key.String(), value)
 		}
-		return nil
+		return dict, nil
 
-	case ast.WhileNode:
-		// Handle while loops (wakati condition { ... })
-		var result interface{}
-		for {
-			condition := Interpret(n.Condition, env)
-			
-			// Convert condition to boolean
-			conditionBool := toBool(condition)
-			
-			if !conditionBool {
-				break
-			}
-			
-			// Execute loop body
-			shouldBreak := false
-			for _, statement := range n.Body {
-				result = Interpret(statement, env)
-				
-				// Check for control flow
-				if cf, ok := result.(ControlFlowResult); ok {
-					if cf.Type == ControlBreak {
-						shouldBreak = true
-						result = cf.Value
-						break
-					} else if cf.Type == ControlContinue {
-						result = cf.Value
-						break // Break inner loop to continue outer loop
-					} else if cf.Type == ControlReturn {
-						return result // Propagate return up
-					}
-				}
-			}
-			
-			if shouldBreak {
-				break
-			}
+	case ast.ArrayNode:
+		// Handle array literals (e.g., [1, 2, 3])
+		elements, err := evalArgs(n.Elements, env)
+		if err != nil {
+			return nil, err
 		}
-		return result
+		return NewArray(elements), nil
 
-	case ast.ForNode:
-		// Handle for loops (kwa init; condition; update { ... })
-		var result interface{}
-		
-		// Execute initialization if present
-		if n.Init != nil {
-			Interpret(n.Init, env)
+	case ast.ArrayAccessNode:
+		// Handle array access (e.g., arr[0]) or dictionary access (e.g., dict["key"])
+		container, err := eval(n.Array, env)
+		if err != nil {
+			return nil, err
 		}
-		
-		// Loop while condition is true
-		for {
-			// Check condition if present
-			if n.Condition != nil {
-				condition := Interpret(n.Condition, env)
-				
-				// Convert condition to boolean
-				conditionBool := toBool(condition)
-				
-				if !conditionBool {
-					break
-				}
-			}
-			
-			// Execute loop body
-			shouldBreak := false
-			shouldContinue := false
-			for _, statement := range n.Body {
-				result = Interpret(statement, env)
-				
-				// Check for control flow
-				if cf, ok := result.(ControlFlowResult); ok {
-					if cf.Type == ControlBreak {
-						shouldBreak = true
-						result = cf.Value
-						break
-					} else if cf.Type == ControlContinue {
-						shouldContinue = true
-						result = cf.Value
-						break
-					} else if cf.Type == ControlReturn {
-						return result // Propagate return up
-					}
-				}
-			}
-			
-			if shouldBreak {
-				break
-			}
-			
-			// Execute update if present and not continuing
-			if !shouldContinue && n.Update != nil {
-				Interpret(n.Update, env)
-			} else if shouldContinue && n.Update != nil {
-				// Still execute update on continue
-				Interpret(n.Update, env)
-			}
-			
-			// If no condition, break after first iteration to prevent infinite loop
-			if n.Condition == nil {
-				break
-			}
+		index, err := eval(n.Index, env)
+		if err != nil {
+			return nil, err
 		}
-		return result
 
-	case ast.BreakNode:
-		// Handle break statements (vunja)
-		return ControlFlowResult{Type: ControlBreak, Value: nil}
+		return getIndex(container, index), nil
 
-	case ast.ContinueNode:
-		// Handle continue statements (endelea)
-		return ControlFlowResult{Type: ControlContinue, Value: nil}
+	case ast.ThisNode:
+		// Handle 'hii' keyword (this/self)
+		return lookupThis(n.Pos, env)
 
-	case ast.TryNode:
-		// Handle try-catch blocks (jaribu ... shika ...)
-		var result interface{}
-		var caughtError interface{}
-		
-		// Execute try block
-		for _, statement := range n.TryBody {
-			result = Interpret(statement, env)
-			
-			// Check for thrown errors or other control flow
-			if cf, ok := result.(ControlFlowResult); ok {
-				if cf.Type == ControlThrow {
-					caughtError = cf.Value
-					break
-				} else if cf.Type == ControlReturn {
-					// Return statements should propagate up
-					if len(n.FinallyBody) > 0 {
-						// Execute finally block before returning
-						for _, statement := range n.FinallyBody {
-							Interpret(statement, env)
-						}
-					}
-					return result
-				}
-			}
+	case ast.MemberAccessNode:
+		// Handle member access (e.g., hii.jina or object.property)
+		object, err := eval(n.Object, env)
+		if err != nil {
+			return nil, err
 		}
-		
-		// If an error was caught, execute catch block
-		if caughtError != nil && len(n.CatchBody) > 0 {
-			// Create new environment for catch block with error variable
-			catchEnv := NewChildEnvironment(env)
-			if n.CatchVar != "" {
-				catchEnv.Set(n.CatchVar, caughtError)
+		return memberOf(object, n.Member, n.Pos)
+
+	case ast.IdentifierNode:
+		return lookupName(n.Value, n.Pos, env)
+
+	case ast.BinaryOpNode:
+		left, err := eval(n.Left, env)
+		if err != nil {
+			return nil, err
+		}
+		right, err := eval(n.Right, env)
+		if err != nil {
+			return nil, err
+		}
+		return binaryOp(n.Op, left, right, n.Pos)
+
+	case ast.UnaryOpNode:
+		operand, err := eval(n.Operand, env)
+		if err != nil {
+			return nil, err
+		}
+		return unaryOp(n.Op, operand, n.Pos)
+
+	case ast.MethodCallNode:
+		// Handle method calls with dot notation (e.g., object.method(args))
+		object, err := eval(n.Object, env)
+		if err != nil {
+			return nil, err
+		}
+		if module, ok := object.(*ModuleValue); ok {
+			// Calling a module's function (e.g., math.ongeza(1, 2))
+			callee, err := module.Member(n.Method, n.Pos)
+			if err != nil {
+				return nil, err
 			}
-			
-			// Execute catch block
-			for _, statement := range n.CatchBody {
-				result = Interpret(statement, catchEnv)
-				
-				// Handle control flow in catch block
-				if cf, ok := result.(ControlFlowResult); ok {
-					if cf.Type == ControlReturn {
-						if len(n.FinallyBody) > 0 {
-							// Execute finally block before returning
-							for _, statement := range n.FinallyBody {
-								Interpret(statement, env)
-							}
-						}
-						return result
-					}
-				}
+			args, err := evalArgs(n.Args, env)
+			if err != nil {
+				return nil, err
 			}
+			return callValue(callee, n.Method, args, n.Pos, env, callFunction)
 		}
-		
-		// Execute finally block if present
-		if len(n.FinallyBody) > 0 {
-			for _, statement := range n.FinallyBody {
-				finallyResult := Interpret(statement, env)
-				
-				// Finally block can override return values
-				if cf, ok := finallyResult.(ControlFlowResult); ok {
-					if cf.Type == ControlReturn {
-						return finallyResult
-					}
-				}
+		if callee, ok := fieldFunction(object, n.Method, env); ok {
+			// A function stored in a field or dictionary (e.g., mtu.callback(1))
+			args, err := evalArgs(n.Args, env)
+			if err != nil {
+				return nil, err
 			}
+			return callValue(callee, n.Method, args, n.Pos, env, callFunction)
 		}
-		
-		// If error wasn't caught, re-throw it
-		if caughtError != nil && len(n.CatchBody) == 0 {
-			return ControlFlowResult{Type: ControlThrow, Value: caughtError}
+		instance, method, err := lookupMethod(object, n.Method, n.Pos, env)
+		if err != nil {
+			return nil, err
 		}
-		
-		return result
 
-	case ast.ThrowNode:
-		// Handle throw statements (tupa)
-		message := Interpret(n.Message, env)
-		errorMsg := fmt.Sprintf("%v", message)
-		return ControlFlowResult{Type: ControlThrow, Value: ErrorValue{Message: errorMsg}}
+		args, err := evalArgs(n.Args, env)
+		if err != nil {
+			return nil, err
+		}
 
-	case ast.ClassNode:
-		// Handle class definitions
-		// Store class definition in environment
-		env.SetClass(n.Name, n)
-		return nil
+		// Set 'hii' to refer to the current instance
+		methodEnv := NewChildEnvironment(env)
+		methodEnv.Set("hii", instance)
+		return callFunction(method.Parameters, method.Body, args, methodEnv)
+
+	case ast.FunctionCallNode:
+		// Built-ins, lambdas and host functions in variables, then user functions
+		args, err := evalArgs(n.Args, env)
+		if err != nil {
+			return nil, err
+		}
+		result, found, err := callByName(n.Name, args, n.Pos, env, callFunction)
+		if !found {
+			return missingFunction(n.Name, n.Pos, env)
+		}
+		return result, err
+
+	case ast.CallNode:
+		// Calling whatever function an expression gives (e.g., hifadhi["f"](2))
+		callee, err := eval(n.Callee, env)
+		if err != nil {
+			return nil, err
+		}
+		args, err := evalArgs(n.Args, env)
+		if err != nil {
+			return nil, err
+		}
+		return callValue(callee, "", args, n.Pos, env, callFunction)
 
 	case ast.NewInstanceNode:
 		// Handle class instantiation (unda ClassName(args))
-		classDef, exists := env.GetClass(n.ClassName)
-		if !exists {
-			return ControlFlowResult{Type: ControlThrow, Value: ErrorValue{Message: fmt.Sprintf("Darasa '%s' halijulikani (Class '%s' not found)", n.ClassName, n.ClassName)}}
+		classDef, err := lookupClass(n.ClassName, n.Pos, env)
+		if err != nil {
+			return nil, err
 		}
 
-		// Create new instance as a dictionary
-		instance := make(map[string]interface{})
-
-		// Collect properties from inheritance chain (parent first, then child)
-		allProperties := collectInheritedProperties(classDef, env)
-		
-		// Initialize properties with default values
-		for _, prop := range allProperties {
-			instance[prop.Name] = nil
+		instance, err := instantiate(classDef, env, func(prop *ast.PropertyNode) (Value, error) {
+			return eval(prop.Value, env)
+		})
+		if err != nil {
+			return nil, err
 		}
 
 		// Call constructor if it exists
 		if classDef.Constructor != nil {
-			// Create environment for constructor
-			constructorEnv := NewChildEnvironment(env)
-			
+			args, err := evalArgs(n.Args, env)
+			if err != nil {
+				return nil, err
+			}
 			// Set 'hii' to refer to the instance
+			constructorEnv := NewChildEnvironment(env)
 			constructorEnv.Set("hii", instance)
+			if _, err := callFunction(classDef.Constructor.Parameters, classDef.Constructor.Body, args, constructorEnv); err != nil {
+				return nil, err
+			}
+		}
+		return instance, nil
 
-			// Bind constructor parameters
-			for i, param := range classDef.Constructor.Parameters {
-				if i < len(n.Args) {
-					argValue := Interpret(n.Args[i], env)
-					constructorEnv.Set(param.Name, argValue)
-				}
+	case ast.LambdaNode:
+		// Return the lambda as a callable value that captures its closure
+		return &FunctionValue{
+			Parameters: n.Parameters,
+			ReturnType: n.ReturnType,
+			Body:       n.Body,
+			Env:        env,
+		}, nil
+
+	default:
+		fmt.Fprintln(env.Streams.Stderr, "Aina ya nodi haijulikani:", n)
+		return Nil, nil
+	}
+}
+
+// binaryOp applies a binary operator to two evaluated operands. Arithmetic needs
+// numbers (or text, for + which joins it); dividing by zero or using an operator
+// on values it does not apply to is an error raised at pos.
+func binaryOp(op string, left, right Value, pos ast.Pos) (Value, error) {
+	switch op {
+	case "na": // AND
+		return BoolValue(toBool(left) && toBool(right)), nil
+	case "au": // OR
+		return BoolValue(toBool(left) || toBool(right)), nil
+	case "==", "!=":
+		if !canCompare(left, right) {
+			return nil, ErrorValue{
+				Message: fmt.Sprintf("Haiwezi kulinganisha %s na %s", left.Type(), right.Type()),
+				Context: fmt.Sprintf("Cannot compare %s with %s", left.Type(), right.Type()),
+				Pos:     pos,
 			}
+		}
+		return BoolValue(valuesEqual(left, right) == (op == "==")), nil
+	case "<", "<=", ">", ">=":
+		return orderValues(op, left, right, pos)
+	}
 
-			// Execute constructor body
-			for _, statement := range classDef.Constructor.Body {
-				result := Interpret(statement, constructorEnv)
-				if cf, ok := result.(ControlFlowResult); ok {
-					if cf.Type == ControlThrow {
-						return cf
-					}
-				}
+	// Handle string concatenation
+	if op == "+" {
+		_, leftIsStr := left.(StringValue)
+		_, rightIsStr := right.(StringValue)
+		if leftIsStr || rightIsStr {
+			return StringValue(left.String() + right.String()), nil
+		}
+	}
+
+	l, leftIsNumber := left.(NumberValue)
+	r, rightIsNumber := right.(NumberValue)
+	if !leftIsNumber || !rightIsNumber {
+		return nil, operandError(op, pos, left, right)
+	}
+	// If either is float, use float arithmetic
+	useFloat := l.IsFloat || r.IsFloat
+	lf, rf := l.AsFloat(), r.AsFloat()
+
+	switch op {
+	case "+":
+		if useFloat {
+			return Float(lf + rf), nil
+		}
+		return Int(l.Int + r.Int), nil
+	case "-":
+		if useFloat {
+			return Float(lf - rf), nil
+		}
+		return Int(l.Int - r.Int), nil
+	case "*":
+		if useFloat {
+			return Float(lf * rf), nil
+		}
+		return Int(l.Int * r.Int), nil
+	case "/":
+		if rf == 0 {
+			return nil, ErrorValue{Message: "Haiwezekani kugawanya na sifuri", Context: "Cannot divide by zero", Pos: pos}
+		}
+		if useFloat {
+			return Float(lf / rf), nil
+		}
+		// Integer division
+		return Int(l.Int / r.Int), nil
+	case "%":
+		// The remainder has the sign of the left operand, so (a / b) * b + a % b == a
+		if rf == 0 {
+			return nil, ErrorValue{Message: "Haiwezekani kuhesabu salio na sifuri", Context: "Cannot calculate modulo with zero", Pos: pos}
+		}
+		if useFloat {
+			return Float(math.Mod(lf, rf)), nil
+		}
+		return Int(l.Int % r.Int), nil
+	case "**":
+		// Integers raised to a non-negative integer power stay exact, unless the
+		// answer is too large for an integer and is worked out in floating point
+		if !useFloat && r.Int >= 0 {
+			if power, ok := intPow(l.Int, r.Int); ok {
+				return Int(power), nil
 			}
 		}
+		if lf == 0 && rf < 0 {
+			return nil, ErrorValue{Message: "Haiwezekani kupandisha sifuri kwa kipeo hasi", Context: "Cannot raise zero to a negative power", Pos: pos}
+		}
+		result := math.Pow(lf, rf)
+		if math.IsNaN(result) {
+			// A negative number to a fractional power, such as (-8) ** 0.5
+			return nil, ErrorValue{Message: "Jibu la " + l.String() + " ** " + r.String() + " si namba halisi",
+				Context: "The result is not a real number", Pos: pos}
+		}
+		if math.IsInf(result, 0) {
+			return nil, ErrorValue{Message: "Jibu la " + l.String() + " ** " + r.String() + " ni kubwa mno",
+				Context: "The result is too large to represent", Pos: pos}
+		}
+		return Float(result), nil
+	}
+	return nil, ErrorValue{
+		Message: fmt.Sprintf("Operesheni '%s' haijulikani", op),
+		Context: fmt.Sprintf("Unknown operator '%s'", op),
+		Pos:     pos,
+	}
+}
 
-		// Store class name in instance for method calls
-		instance["__class__"] = n.ClassName
+// orderValues compares two numbers, or two pieces of text alphabetically
+func orderValues(op string, left, right Value, pos ast.Pos) (Value, error) {
+	var order int
+	switch l := left.(type) {
+	case NumberValue:
+		r, ok := right.(NumberValue)
+		if !ok {
+			return nil, operandError(op, pos, left, right)
+		}
+		order = cmp.Compare(l.AsFloat(), r.AsFloat())
+	case StringValue:
+		r, ok := right.(StringValue)
+		if !ok {
+			return nil, operandError(op, pos, left, right)
+		}
+		order = strings.Compare(string(l), string(r))
+	default:
+		return nil, operandError(op, pos, left, right)
+	}
 
-		return instance
+	switch op {
+	case "<":
+		return BoolValue(order < 0), nil
+	case "<=":
+		return BoolValue(order <= 0), nil
+	case ">":
+		return BoolValue(order > 0), nil
+	default:
+		return BoolValue(order >= 0), nil
+	}
+}
 
-	case ast.LambdaNode:
-		// Handle lambda functions - return the lambda as a callable value
-		// Store the lambda with its closure environment
-		lambdaValue := map[string]interface{}{
-			"__type__":      "lambda",
-			"__parameters__": n.Parameters,
-			"__return_type__": n.ReturnType,
-			"__body__":      n.Body,
-			"__env__":       env, // Capture closure
+// operandError is the error for an operator used on values it does not apply to
+func operandError(op string, pos ast.Pos, operands ...Value) error {
+	types := make([]string, len(operands))
+	for i, operand := range operands {
+		types[i] = operand.Type()
+	}
+	return ErrorValue{
+		Message: fmt.Sprintf("Operesheni '%s' haiwezi kutumika kwa %s", op, strings.Join(types, " na ")),
+		Context: fmt.Sprintf("Cannot use '%s' with %s", op, strings.Join(types, " and ")),
+		Pos:     pos,
+	}
+}
+
+// unaryOp applies a unary operator to an evaluated operand
+func unaryOp(op string, operand Value, pos ast.Pos) (Value, error) {
+	switch op {
+	case "-":
+		// Numeric negation keeps the operand's int/float kind
+		number, ok := operand.(NumberValue)
+		if !ok {
+			return nil, operandError(op, pos, operand)
+		}
+		if number.IsFloat {
+			return Float(-number.Float), nil
+		}
+		return Int(-number.Int), nil
+	case "si": // NOT
+		return BoolValue(!toBool(operand)), nil
+	}
+	return nil, ErrorValue{
+		Message: fmt.Sprintf("Operesheni '%s' haijulikani", op),
+		Context: fmt.Sprintf("Unknown operator '%s'", op),
+		Pos:     pos,
+	}
+}
+
+// numberLiteral converts the text of a number literal; literals with a decimal point are floats
+func numberLiteral(text string) NumberValue {
+	if strings.Contains(text, ".") {
+		if value, err := strconv.ParseFloat(text, 64); err == nil {
+			return Float(value)
 		}
-		return lambdaValue
+	}
+	// Fall back to integer
+	value, _ := strconv.Atoi(text)
+	return Int(value)
+}
 
-	case ast.FunctionNode:
-		// Handle function definitions
-		if n.Name == "kuu" {
-			// Execute main function immediately
-			var result interface{}
-			for _, statement := range n.Body {
-				result = Interpret(statement, env)
-				
-				// Handle return from main function
-				if cf, ok := result.(ControlFlowResult); ok {
-					if cf.Type == ControlReturn {
-						return cf.Value
-					} else if cf.Type == ControlThrow {
-						// Unhandled error in main function
-						if err, ok := cf.Value.(ErrorValue); ok {
-							fmt.Printf("\n╔═══════════════════════════════════════════════════════════╗\n")
-							fmt.Printf("║ HITILAFU (ERROR)                                          ║\n")
-							fmt.Printf("╚═══════════════════════════════════════════════════════════╝\n")
-							fmt.Printf("Ujumbe: %s\n", err.Message)
-							if err.Context != "" {
-								fmt.Printf("Muktadha: %s\n", err.Context)
-							}
-							fmt.Printf("\n")
-						} else {
-							fmt.Printf("Hitilafu isiyoshughulikiwa: %v\n", cf.Value)
-						}
-						return nil
-					}
-					// Other control flow statements outside loops are ignored
-					result = nil
-				}
-			}
-			return result
-		} else {
-			// Store user-defined function
-			env.SetFunction(n.Name, n)
-			return nil
+// lookupName returns the value an identifier refers to: a variable, then a class,
+// then an imported module. An undefined name is an error that suggests a similar
+// name in scope, or in legacy mode the identifier's own name as text.
+func lookupName(name string, pos ast.Pos, env *Environment) (Value, error) {
+	if value := env.Get(name); value != nil {
+		return value, nil
+	}
+	if function, exists := env.GetFunction(name); exists {
+		return functionValue(function, env), nil
+	}
+	if class, exists := env.GetClass(name); exists {
+		return &ClassValue{Definition: class}, nil
+	}
+	if module, exists := env.Modules[name]; exists {
+		return module, nil
+	}
+	if env.Options.Legacy {
+		return StringValue(name), nil
+	}
+
+	err := ErrorValue{
+		Message: fmt.Sprintf("Jina '%s' halijulikani", name),
+		Context: fmt.Sprintf("'%s' is not defined", name),
+		Pos:     pos,
+	}
+	if suggestion := suggestName(name, valueNames(env)); suggestion != "" {
+		err.Message += fmt.Sprintf("; ulimaanisha '%s'?", suggestion)
+		err.Context += fmt.Sprintf("; did you mean '%s'?", suggestion)
+	}
+	return nil, err
+}
+
+// functionValue turns a named kazi into a value that can be passed around. It
+// runs in a scope inside the global one of the program or module env belongs to.
+func functionValue(function ast.FunctionNode, env *Environment) *FunctionValue {
+	for env.Parent != nil {
+		env = env.Parent
+	}
+	return &FunctionValue{
+		Name:       function.Name,
+		Parameters: function.Parameters,
+		ReturnType: function.ReturnType,
+		Body:       function.Body,
+		Env:        env,
+	}
+}
+
+// lookupThis returns the instance 'hii' refers to
+func lookupThis(pos ast.Pos, env *Environment) (Value, error) {
+	value := env.Get("hii")
+	if value == nil {
+		return nil, ErrorValue{Message: "'hii' inaweza kutumika tu ndani ya darasa (this can only be used inside a class)", Pos: pos}
+	}
+	return value, nil
+}
+
+// lookupClass finds a class definition by name
+func lookupClass(name string, pos ast.Pos, env *Environment) (ast.ClassNode, error) {
+	class, exists := env.GetClass(name)
+	if !exists {
+		return class, ErrorValue{Message: fmt.Sprintf("Darasa '%s' halijulikani (Class '%s' not found)", name, name), Pos: pos}
+	}
+	return class, nil
+}
+
+// lookupMethod finds the method called on object, searching its class and the parent chain
+func lookupMethod(object Value, name string, pos ast.Pos, env *Environment) (*InstanceValue, *ast.FunctionNode, error) {
+	instance, ok := object.(*InstanceValue)
+	if !ok {
+		return nil, nil, ErrorValue{
+			Message: fmt.Sprintf("Haiwezi kuita mbinu '%s' kwenye thamani ya aina %s; si instance ya darasa", name, object.Type()),
+			Context: fmt.Sprintf("Cannot call method '%s' on a value of type %s, which is not a class instance", name, object.Type()),
+			Pos:     pos,
 		}
+	}
 
-	default:
-		fmt.Println("Aina ya nodi haijulikani:", n)
-		return nil
+	className := instance.Class.Definition.Name
+	method := findMethodInClass(className, name, env)
+	if method == nil {
+		return nil, nil, ErrorValue{
+			Message: fmt.Sprintf("Mbinu '%s' haipatikani katika darasa '%s'", name, className),
+			Context: fmt.Sprintf("Method '%s' not found in class '%s'", name, className),
+			Pos:     pos,
+		}
+	}
+	return instance, method, nil
+}
+
+// instantiate creates an instance of class with every property, inherited ones first,
+// set to its default value (computed by evalDefault) or nil
+func instantiate(class ast.ClassNode, env *Environment, evalDefault func(*ast.PropertyNode) (Value, error)) (*InstanceValue, error) {
+	instance := &InstanceValue{Class: &ClassValue{Definition: class}, Fields: NewDict()}
+	for _, prop := range collectInheritedProperties(class, env) {
+		var value Value = Nil
+		if prop.Value != nil {
+			defaultValue, err := evalDefault(prop)
+			if err != nil {
+				return nil, err
+			}
+			value = defaultValue
+		}
+		instance.Fields.Set(prop.Name, value)
 	}
+	return instance, nil
 }
 
 // collectInheritedProperties collects all properties from the class and its parent chain
-func collectInheritedProperties(class ast.ClassNode, env *Environment) []ast.PropertyNode {
-	var properties []ast.PropertyNode
-	
+func collectInheritedProperties(class ast.ClassNode, env *Environment) []*ast.PropertyNode {
+	var properties []*ast.PropertyNode
+
 	// First, collect parent properties if there's a parent
 	if class.Parent != "" {
 		parentClass, exists := env.GetClass(class.Parent)
@@ -1348,17 +1167,19 @@ func collectInheritedProperties(class ast.ClassNode, env *Environment) []ast.Pro
 			properties = append(properties, collectInheritedProperties(parentClass, env)...)
 		}
 	}
-	
+
 	// Then add this class's properties
-	properties = append(properties, class.Properties...)
-	
+	for i := range class.Properties {
+		properties = append(properties, &class.Properties[i])
+	}
+
 	return properties
 }
 
 // collectInheritedMethods collects all methods from the class and its parent chain
 func collectInheritedMethods(class ast.ClassNode, env *Environment) []ast.FunctionNode {
 	methodMap := make(map[string]ast.FunctionNode)
-	
+
 	// First, collect parent methods if there's a parent
 	if class.Parent != "" {
 		parentClass, exists := env.GetClass(class.Parent)
@@ -1369,18 +1190,18 @@ func collectInheritedMethods(class ast.ClassNode, env *Environment) []ast.Functi
 			}
 		}
 	}
-	
+
 	// Then add/override with this class's methods
 	for _, method := range class.Methods {
 		methodMap[method.Name] = method
 	}
-	
+
 	// Convert map back to slice
 	var methods []ast.FunctionNode
 	for _, method := range methodMap {
 		methods = append(methods, method)
 	}
-	
+
 	return methods
 }
 
@@ -1390,18 +1211,18 @@ func findMethodInClass(className string, methodName string, env *Environment) *a
 	if !exists {
 		return nil
 	}
-	
+
 	// Check this class's methods
 	for _, method := range classDef.Methods {
 		if method.Name == methodName {
 			return &method
 		}
 	}
-	
+
 	// Check parent class if exists
 	if classDef.Parent != "" {
 		return findMethodInClass(classDef.Parent, methodName, env)
 	}
-	
+
 	return nil
 }
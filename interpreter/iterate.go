@@ -0,0 +1,71 @@
+package interpreter
+
+import (
+	"fmt"
+	"kwenda/ast"
+)
+
+// iterator steps through the items of an orodha, kamusi or maneno for kwa kila.
+// The items are taken when the loop starts, so changing the collection inside
+// the loop does not change which items it visits.
+type iterator struct {
+	keys   []Value // Index or key of each item
+	values []Value // Each item
+	pairs  bool    // Whether the loop names both the key and the item
+	next   int     // Position of the next item
+}
+
+func (it *iterator) Type() string   { return "kipitishi" }
+func (it *iterator) String() string { return "<kipitishi>" }
+
+// iterate starts going through value with the given number of loop variables.
+// With one variable a kamusi gives its keys; otherwise each item is paired with
+// its index (orodha, maneno) or key (kamusi).
+func iterate(value Value, names int, pos ast.Pos) (*iterator, error) {
+	it := &iterator{pairs: names == 2}
+	switch v := value.(type) {
+	case *ArrayValue:
+		it.values = append([]Value(nil), v.Elements...)
+		for i := range it.values {
+			it.keys = append(it.keys, Int(i))
+		}
+	case *DictValue:
+		for _, key := range v.Keys {
+			it.keys = append(it.keys, StringValue(key))
+			it.values = append(it.values, v.Entries[key])
+		}
+		if !it.pairs {
+			it.values = it.keys
+		}
+	case StringValue:
+		for i, char := range []rune(string(v)) {
+			it.keys = append(it.keys, Int(i))
+			it.values = append(it.values, StringValue(char))
+		}
+	default:
+		return nil, ErrorValue{
+			Message: fmt.Sprintf("Haiwezekani kupitia %s kwa 'kwa kila'", value.Type()),
+			Context: fmt.Sprintf("Cannot loop over %s; kwa kila goes through an orodha, kamusi or maneno", value.Type()),
+			Pos:     pos,
+		}
+	}
+	return it, nil
+}
+
+// step returns the next item and its key, or false when every item has been visited
+func (it *iterator) step() (key, value Value, ok bool) {
+	if it.next >= len(it.values) {
+		return nil, nil, false
+	}
+	key, value = it.keys[it.next], it.values[it.next]
+	it.next++
+	return key, value, true
+}
+
+// loopVariables returns how many variables a for-each loop names
+func loopVariables(loop ast.ForEachNode) int {
+	if loop.Key != "" {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,129 @@
+package interpreter
+
+import (
+	"fmt"
+	"kwenda/ast"
+)
+
+// matchArm reports whether value matches any of the patterns of a chagua arm. The
+// variables bound by the first pattern that matches are stored in env; a pattern
+// that fails part way stores nothing.
+func matchArm(patterns []ast.ASTNode, value Value, env *Environment) (bool, error) {
+	for _, pattern := range patterns {
+		bindings := make(map[string]Value)
+		matched, err := matchPattern(pattern, value, bindings, env)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			for name, bound := range bindings {
+				env.Set(name, bound)
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchPattern reports whether value matches pattern, collecting the variables
+// the pattern binds in bindings
+func matchPattern(pattern ast.ASTNode, value Value, bindings map[string]Value, env *Environment) (bool, error) {
+	switch p := pattern.(type) {
+	case ast.LiteralPattern:
+		// Literals only match values of their own type, so 1 does not match "1"
+		expected, err := eval(p.Value, env)
+		if err != nil {
+			return false, err
+		}
+		return value.Type() == expected.Type() && valuesEqual(value, expected), nil
+
+	case ast.RangePattern:
+		number, ok := value.(NumberValue)
+		if !ok {
+			return false, nil
+		}
+		low, high := numberLiteral(p.Low.Value), numberLiteral(p.High.Value)
+		return number.AsFloat() >= low.AsFloat() && number.AsFloat() <= high.AsFloat(), nil
+
+	case ast.BindingPattern:
+		if p.Name != "_" {
+			bindings[p.Name] = value
+		}
+		return true, nil
+
+	case ast.ArrayPattern:
+		array, ok := value.(*ArrayValue)
+		if !ok || len(array.Elements) != len(p.Elements) {
+			return false, nil
+		}
+		for i, element := range p.Elements {
+			if matched, err := matchPattern(element, array.Elements[i], bindings, env); !matched || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case ast.DictPattern:
+		dict, ok := value.(*DictValue)
+		if !ok {
+			return false, nil
+		}
+		for i, key := range p.Keys {
+			entry, exists := dict.Get(key)
+			if !exists {
+				return false, nil
+			}
+			if matched, err := matchPattern(p.Values[i], entry, bindings, env); !matched || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case ast.InstancePattern:
+		class, exists := env.GetClass(p.ClassName)
+		if !exists {
+			return false, ErrorValue{
+				Message: fmt.Sprintf("Darasa '%s' halijulikani", p.ClassName),
+				Context: fmt.Sprintf("The pattern %s(...) names an unknown class", p.ClassName),
+				Pos:     p.Pos,
+			}
+		}
+		instance, ok := value.(*InstanceValue)
+		if !ok || !instanceOf(instance.Class.Definition, class.Name, env) {
+			return false, nil
+		}
+		for i, property := range p.Properties {
+			field, exists := instance.Fields.Get(property)
+			if !exists {
+				return false, ErrorValue{
+					Message: fmt.Sprintf("Darasa '%s' halina sifa '%s'", class.Name, property),
+					Context: fmt.Sprintf("Class '%s' has no property '%s' to match", class.Name, property),
+					Pos:     ast.PosOf(p.Patterns[i]),
+				}
+			}
+			if matched, err := matchPattern(p.Patterns[i], field, bindings, env); !matched || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return false, ErrorValue{
+		Message: fmt.Sprintf("Muundo wa kulinganisha haujulikani: %T", pattern),
+		Context: fmt.Sprintf("Unknown pattern %T", pattern),
+		Pos:     ast.PosOf(pattern),
+	}
+}
+
+// instanceOf reports whether class is the class called name or inherits from it
+func instanceOf(class ast.ClassNode, name string, env *Environment) bool {
+	for {
+		if class.Name == name {
+			return true
+		}
+		parent, exists := env.GetClass(class.Parent)
+		if class.Parent == "" || !exists {
+			return false
+		}
+		class = parent
+	}
+}
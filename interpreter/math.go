@@ -0,0 +1,292 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+)
+
+func init() {
+	RegisterNativeModule(mathModule)
+}
+
+// mathModule is the native half of the standard math module (leta "hesabu"):
+// exact constants and the functions that need floating point or are too slow to
+// write in Kwenda. Integer arguments give integer results wherever the answer
+// is a whole number.
+var mathModule = NativeModule{
+	Name: "math",
+	Constants: []NativeConstant{
+		{Name: "PI", Value: Float(math.Pi), Doc: "Uwiano wa mzingo wa duara kwa kipenyo chake", DocEnglish: "the ratio of a circle's circumference to its diameter"},
+		{Name: "E", Value: Float(math.E), Doc: "Msingi wa logariti asilia", DocEnglish: "the base of the natural logarithm"},
+		{Name: "TAU", Value: Float(2 * math.Pi), Doc: "Mara mbili ya PI", DocEnglish: "twice PI"},
+	},
+	Functions: []Builtin{
+		{
+			Name: "kiwango", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"namba"}, ReturnType: "namba",
+			Doc:        "Thamani kamili ya namba (bila alama ya hasi)",
+			DocEnglish: "absolute value",
+			Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+				if !x[0].IsFloat {
+					if x[0].Int < 0 {
+						return Int(-x[0].Int), nil
+					}
+					return x[0], nil
+				}
+				return Float(math.Abs(x[0].Float)), nil
+			}),
+		},
+		{
+			Name: "nguvu", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"namba", "namba"}, ReturnType: "namba",
+			Doc:        "Msingi ukipandishwa kwa kipeo; kamili kwa namba kamili isipokuwa jibu ni kubwa mno",
+			DocEnglish: "base raised to a power; exact for integers unless the answer is too large for one",
+			Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+				if !x[0].IsFloat && !x[1].IsFloat && x[1].Int >= 0 {
+					// Answers too large for an integer are worked out in floating point
+					if power, ok := intPow(x[0].Int, x[1].Int); ok {
+						return Int(power), nil
+					}
+				}
+				if x[0].AsFloat() == 0 && x[1].AsFloat() < 0 {
+					return nil, builtinError(call, "Haiwezekani kupandisha sifuri kwa kipeo hasi", "cannot raise zero to a negative power")
+				}
+				return floatResult(call, math.Pow(x[0].AsFloat(), x[1].AsFloat()))
+			}),
+		},
+		{
+			Name: "kipeuo", MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"namba"}, ReturnType: "namba",
+			Doc:        "Kipeuo cha pili (square root)",
+			DocEnglish: "square root",
+			Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+				if x[0].AsFloat() < 0 {
+					return nil, builtinError(call, "Haiwezekani kupata kipeuo cha namba hasi", "cannot take the square root of a negative number")
+				}
+				return floatResult(call, math.Sqrt(x[0].AsFloat()))
+			}),
+		},
+		unaryFloat("sin", "Sini ya pembe katika radiani", "sine of an angle in radians", math.Sin, nil),
+		unaryFloat("cos", "Kosini ya pembe katika radiani", "cosine of an angle in radians", math.Cos, nil),
+		unaryFloat("tan", "Tanjenti ya pembe katika radiani", "tangent of an angle in radians", math.Tan, nil),
+		unaryFloat("asin", "Pembe (radiani) yenye sini hii", "arcsine, in radians", math.Asin, withinOne),
+		unaryFloat("acos", "Pembe (radiani) yenye kosini hii", "arccosine, in radians", math.Acos, withinOne),
+		unaryFloat("atan", "Pembe (radiani) yenye tanjenti hii", "arctangent, in radians", math.Atan, nil),
+		{
+			Name: "atan2", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"namba", "namba"}, ReturnType: "namba",
+			Doc:        "Pembe (radiani) ya nukta (x, y), ikipewa y kisha x",
+			DocEnglish: "angle in radians of the point (x, y), given y then x",
+			Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+				return floatResult(call, math.Atan2(x[0].AsFloat(), x[1].AsFloat()))
+			}),
+		},
+		unaryFloat("log", "Logariti asilia", "natural logarithm", math.Log, positive),
+		unaryFloat("log10", "Logariti ya msingi 10", "base-10 logarithm", math.Log10, positive),
+		unaryFloat("log2", "Logariti ya msingi 2", "base-2 logarithm", math.Log2, positive),
+		unaryFloat("exp", "E ikipandishwa kwa namba", "E raised to a power", math.Exp, nil),
+		rounding("sakafu", "Namba kamili kubwa zaidi isiyozidi namba", "round down (floor)", math.Floor),
+		rounding("dari", "Namba kamili ndogo zaidi isiyopungua namba", "round up (ceiling)", math.Ceil),
+		rounding("kadiria", "Namba kamili iliyo karibu zaidi; nusu huenda mbali na sifuri", "round to the nearest integer, halves away from zero", math.Round),
+		{
+			Name: "ndogo", MinArgs: 1, MaxArgs: -1, ArgTypes: []string{"namba"}, ReturnType: "namba",
+			Doc:        "Namba ndogo zaidi kati ya zilizotolewa",
+			DocEnglish: "smallest of the numbers (min)",
+			Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+				least := x[0]
+				for _, n := range x[1:] {
+					if n.AsFloat() < least.AsFloat() {
+						least = n
+					}
+				}
+				return least, nil
+			}),
+		},
+		{
+			Name: "kubwa", MinArgs: 1, MaxArgs: -1, ArgTypes: []string{"namba"}, ReturnType: "namba",
+			Doc:        "Namba kubwa zaidi kati ya zilizotolewa",
+			DocEnglish: "largest of the numbers (max)",
+			Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+				greatest := x[0]
+				for _, n := range x[1:] {
+					if n.AsFloat() > greatest.AsFloat() {
+						greatest = n
+					}
+				}
+				return greatest, nil
+			}),
+		},
+		{
+			// gawanya_kamili and salio round down (floored division), unlike / and %
+			// between whole numbers, which round toward zero: gawanya_kamili(-7, 2) is
+			// -4 where -7 / 2 is -3, and salio(-7, 3) is 2 where -7 % 3 is -1
+			Name: "gawanya_kamili", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"namba", "namba"}, ReturnType: "namba",
+			Doc:        "Mgawanyo uliokadiriwa chini hadi namba kamili; tofauti na /, gawanya_kamili(-7, 2) ni -4",
+			DocEnglish: "division rounded down to an integer; unlike /, which rounds toward zero, gawanya_kamili(-7, 2) is -4",
+			Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+				if x[1].AsFloat() == 0 {
+					return nil, builtinError(call, "Haiwezekani kugawanya na sifuri", "cannot divide by zero")
+				}
+				if !x[0].IsFloat && !x[1].IsFloat {
+					quotient := x[0].Int / x[1].Int
+					if x[0].Int%x[1].Int != 0 && (x[0].Int < 0) != (x[1].Int < 0) {
+						quotient--
+					}
+					return Int(quotient), nil
+				}
+				return wholeNumber(call, math.Floor(x[0].AsFloat()/x[1].AsFloat()))
+			}),
+		},
+		{
+			Name: "salio", MinArgs: 2, MaxArgs: 2, ArgTypes: []string{"namba", "namba"}, ReturnType: "namba",
+			Doc:        "Kinachobaki baada ya gawanya_kamili; kina alama ya kigawanyo, tofauti na %, kwa hivyo salio(-7, 3) ni 2",
+			DocEnglish: "remainder after gawanya_kamili, with the sign of the divisor; unlike %, which takes the sign of the dividend, salio(-7, 3) is 2",
+			Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+				if x[1].AsFloat() == 0 {
+					return nil, builtinError(call, "Haiwezekani kuhesabu salio na sifuri", "cannot calculate modulo with zero")
+				}
+				if !x[0].IsFloat && !x[1].IsFloat {
+					remainder := x[0].Int % x[1].Int
+					if remainder != 0 && (remainder < 0) != (x[1].Int < 0) {
+						remainder += x[1].Int
+					}
+					return Int(remainder), nil
+				}
+				remainder := math.Mod(x[0].AsFloat(), x[1].AsFloat())
+				if remainder != 0 && (remainder < 0) != (x[1].AsFloat() < 0) {
+					remainder += x[1].AsFloat()
+				}
+				return Float(remainder), nil
+			}),
+		},
+		{
+			Name: "bana", MinArgs: 3, MaxArgs: 3, ArgTypes: []string{"namba", "namba", "namba"}, ReturnType: "namba",
+			Doc:        "Namba ikibanwa kati ya kiwango cha chini na cha juu",
+			DocEnglish: "number limited to a lowest and highest value (clamp)",
+			Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+				value, low, high := x[0], x[1], x[2]
+				if low.AsFloat() > high.AsFloat() {
+					return nil, builtinError(call,
+						fmt.Sprintf("Kiwango cha chini %s ni kikubwa kuliko cha juu %s", low, high),
+						fmt.Sprintf("the lower bound %s is greater than the upper bound %s", low, high))
+				}
+				if value.AsFloat() < low.AsFloat() {
+					return low, nil
+				}
+				if value.AsFloat() > high.AsFloat() {
+					return high, nil
+				}
+				return value, nil
+			}),
+		},
+	},
+}
+
+// numberFunc wraps a math function so that it receives its arguments as numbers,
+// failing with an error if any argument is not a number
+func numberFunc(impl func(call *BuiltinCall, x []NumberValue) (Value, error)) BuiltinFunc {
+	return func(call *BuiltinCall) (Value, error) {
+		numbers := make([]NumberValue, len(call.Args))
+		for i, arg := range call.Args {
+			n, ok := arg.(NumberValue)
+			if !ok {
+				return nil, builtinError(call,
+					fmt.Sprintf("Hoja ya %d lazima iwe namba, lakini ni %s", i+1, arg.Type()),
+					fmt.Sprintf("argument %d must be a number, but is %s", i+1, arg.Type()))
+			}
+			numbers[i] = n
+		}
+		return impl(call, numbers)
+	}
+}
+
+// unaryFloat describes a function of one number computed in floating point. valid,
+// if given, rejects arguments outside the function's domain with an error.
+func unaryFloat(name, doc, docEnglish string, fn func(float64) float64, valid func(call *BuiltinCall, x float64) error) Builtin {
+	return Builtin{
+		Name: name, MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"namba"}, ReturnType: "namba",
+		Doc: doc, DocEnglish: docEnglish,
+		Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+			if valid != nil {
+				if err := valid(call, x[0].AsFloat()); err != nil {
+					return nil, err
+				}
+			}
+			return floatResult(call, fn(x[0].AsFloat()))
+		}),
+	}
+}
+
+// rounding describes a function that rounds a number to an integer
+func rounding(name, doc, docEnglish string, fn func(float64) float64) Builtin {
+	return Builtin{
+		Name: name, MinArgs: 1, MaxArgs: 1, ArgTypes: []string{"namba"}, ReturnType: "namba",
+		Doc: doc, DocEnglish: docEnglish,
+		Impl: numberFunc(func(call *BuiltinCall, x []NumberValue) (Value, error) {
+			if !x[0].IsFloat {
+				return x[0], nil
+			}
+			return wholeNumber(call, fn(x[0].Float))
+		}),
+	}
+}
+
+// positive is the domain of the logarithms
+func positive(call *BuiltinCall, x float64) error {
+	if x <= 0 {
+		return builtinError(call, "Logariti inahitaji namba chanya", "logarithm needs a positive number")
+	}
+	return nil
+}
+
+// withinOne is the domain of asin and acos
+func withinOne(call *BuiltinCall, x float64) error {
+	if x < -1 || x > 1 {
+		return builtinError(call, "Namba lazima iwe kati ya -1 na 1", "the number must be between -1 and 1")
+	}
+	return nil
+}
+
+// floatResult returns a floating-point answer, which must be a finite number
+func floatResult(call *BuiltinCall, f float64) (Value, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, builtinError(call, "Jibu ni kubwa mno au si namba", "the result is too large or not a number")
+	}
+	return Float(f), nil
+}
+
+// wholeNumber returns a float that holds a whole number as an integer
+func wholeNumber(call *BuiltinCall, f float64) (Value, error) {
+	if math.IsNaN(f) || f >= math.MaxInt64 || f < math.MinInt64 {
+		return nil, builtinError(call, "Jibu ni kubwa mno au si namba", "the result is too large or not a number")
+	}
+	return Int(int(f)), nil
+}
+
+// intPow raises base to a non-negative exponent by repeated squaring. ok is false
+// when the answer does not fit in an integer.
+func intPow(base, exponent int) (result int, ok bool) {
+	result = 1
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			if result, ok = mulInt(result, base); !ok {
+				return 0, false
+			}
+		}
+		exponent >>= 1
+		if exponent > 0 {
+			if base, ok = mulInt(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+// mulInt multiplies two integers, reporting false if the product overflows
+func mulInt(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, false
+	}
+	return product, true
+}
@@ -0,0 +1,174 @@
+package interpreter
+
+import (
+	"fmt"
+	"kwenda/ast"
+	"sync"
+)
+
+// ModuleValue is a module brought in with leta: the global scope its file ran in,
+// and the names it makes public with umma
+type ModuleValue struct {
+	Name    string
+	Env     *Environment
+	Exports map[string]bool // Names declared umma; nil when the module exports everything
+}
+
+// NewModule creates a module over the scope its file ran in. A module that
+// declares nothing umma exports every name.
+func NewModule(name string, env *Environment, exports []string) *ModuleValue {
+	module := &ModuleValue{Name: name, Env: env}
+	if len(exports) > 0 {
+		module.Exports = make(map[string]bool, len(exports))
+		for _, export := range exports {
+			module.Exports[export] = true
+		}
+	}
+	return module
+}
+
+func (m *ModuleValue) Type() string   { return "moduli" }
+func (m *ModuleValue) String() string { return "<moduli " + m.Name + ">" }
+
+// Member returns the exported variable, function or class called name. Functions
+// come back as values that run inside the module, so they can still reach the
+// module's private helpers and variables.
+func (m *ModuleValue) Member(name string, pos ast.Pos) (Value, error) {
+	value, exists := m.lookup(name)
+	if !exists {
+		return nil, ErrorValue{
+			Message: fmt.Sprintf("'%s' haipatikani katika moduli '%s'", name, m.Name),
+			Context: fmt.Sprintf("Module '%s' has no '%s'", m.Name, name),
+			Pos:     pos,
+		}
+	}
+	if m.Exports != nil && !m.Exports[name] {
+		return nil, ErrorValue{
+			Message: fmt.Sprintf("'%s' si ya umma katika moduli '%s'", name, m.Name),
+			Context: fmt.Sprintf("'%s' is private to module '%s'; mark it umma to export it", name, m.Name),
+			Pos:     pos,
+		}
+	}
+	return value, nil
+}
+
+// lookup finds a top-level name in the module, exported or not
+func (m *ModuleValue) lookup(name string) (Value, bool) {
+	if value, exists := m.Env.Variables[name]; exists {
+		return value, true
+	}
+	if function, exists := m.Env.GetFunction(name); exists {
+		return functionValue(function, m.Env), true
+	}
+	if class, exists := m.Env.GetClass(name); exists {
+		return &ClassValue{Definition: class}, true
+	}
+	return nil, false
+}
+
+// ImportInto binds the named exports directly in env (kutoka "..." leta a, b):
+// classes become classes of env and everything else becomes a variable
+func (m *ModuleValue) ImportInto(env *Environment, names []string, pos ast.Pos) error {
+	for _, name := range names {
+		value, err := m.Member(name, pos)
+		if err != nil {
+			return err
+		}
+		if class, ok := value.(*ClassValue); ok {
+			env.SetClass(name, class.Definition)
+			continue
+		}
+		env.Set(name, value)
+	}
+	return nil
+}
+
+// NativeModule is the part of a module written in Go. Its constants and functions
+// are added to the module whose file declares moduli with the same name, before
+// the file runs, so the file can build on them and take their place.
+type NativeModule struct {
+	Name      string
+	Constants []NativeConstant
+	Functions []Builtin
+}
+
+// NativeConstant is a value a native module provides, such as math.PI
+type NativeConstant struct {
+	Name       string
+	Value      Value
+	Doc        string // Swahili description
+	DocEnglish string // English description
+}
+
+var (
+	nativeModulesMu sync.RWMutex
+	nativeModules   = make(map[string]*NativeModule)
+)
+
+// RegisterNativeModule adds a native module, replacing any with the same name
+func RegisterNativeModule(m NativeModule) {
+	nativeModulesMu.Lock()
+	defer nativeModulesMu.Unlock()
+	nativeModules[m.Name] = &m
+}
+
+// LookupNativeModule finds the native module for the module called name
+func LookupNativeModule(name string) (*NativeModule, bool) {
+	nativeModulesMu.RLock()
+	defer nativeModulesMu.RUnlock()
+	m, exists := nativeModules[name]
+	return m, exists
+}
+
+// Names lists the constants and functions the module provides
+func (m *NativeModule) Names() []string {
+	names := make([]string, 0, len(m.Constants)+len(m.Functions))
+	for _, c := range m.Constants {
+		names = append(names, c.Name)
+	}
+	for _, f := range m.Functions {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Install defines the module's constants as variables of env and its functions
+// as host functions that check how many arguments they are given and their types
+func (m *NativeModule) Install(env *Environment) {
+	for _, c := range m.Constants {
+		env.Set(c.Name, c.Value)
+	}
+	for i := range m.Functions {
+		b := &m.Functions[i]
+		name := m.Name + "." + b.Name
+		env.Set(b.Name, &HostFunction{Name: name, Impl: func(call *BuiltinCall) (Value, error) {
+			// Errors name the function by its module whatever it was called through
+			call.Name = name
+			if !b.Accepts(len(call.Args)) {
+				return nil, wrongArgumentCount(call.Name, b, len(call.Args), call.Pos)
+			}
+			return b.Call(call)
+		}})
+	}
+}
+
+// wrongArgumentCount is the error for calling the built-in or native function b
+// with argc arguments it does not accept
+func wrongArgumentCount(name string, b *Builtin, argc int, pos ast.Pos) error {
+	want, wantEnglish := fmt.Sprint(b.MinArgs), fmt.Sprint(b.MinArgs)
+	switch {
+	case b.MaxArgs < 0:
+		want, wantEnglish = fmt.Sprintf("%d au zaidi", b.MinArgs), fmt.Sprintf("%d or more", b.MinArgs)
+	case b.MaxArgs != b.MinArgs:
+		want, wantEnglish = fmt.Sprintf("%d hadi %d", b.MinArgs, b.MaxArgs), fmt.Sprintf("%d to %d", b.MinArgs, b.MaxArgs)
+	}
+	arguments := "arguments"
+	if wantEnglish == "1" {
+		arguments = "argument"
+	}
+	return ErrorValue{
+		Message: fmt.Sprintf("'%s' inahitaji hoja %s lakini imepewa %d", name, want, argc),
+		Context: fmt.Sprintf("'%s' takes %s %s but was given %d", name, wantEnglish, arguments, argc),
+		Pos:     pos,
+	}
+}
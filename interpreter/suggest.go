@@ -0,0 +1,101 @@
+package interpreter
+
+import "sort"
+
+// suggestName returns the candidate closest to a misspelt name, or "" if none is
+// close enough to be what the programmer meant. Names within one edit (two for
+// longer names) qualify; ties go to the alphabetically first candidate.
+func suggestName(name string, candidates []string) string {
+	limit := 1
+	if len([]rune(name)) > 5 {
+		limit = 2
+	}
+	best, bestDistance := "", limit+1
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		distance := editDistance(name, candidate)
+		if distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// valueNames lists every name an identifier could refer to in env: variables in
+// this and the enclosing scopes, classes and modules
+func valueNames(env *Environment) []string {
+	seen := make(map[string]bool)
+	for scope := env; scope != nil; scope = scope.Parent {
+		for name := range scope.Variables {
+			seen[name] = true
+		}
+	}
+	for name := range env.Classes {
+		seen[name] = true
+	}
+	for name := range env.Modules {
+		seen[name] = true
+	}
+	return sortedNames(seen)
+}
+
+// functionNames lists every name a call could refer to in env: built-ins, user
+// functions and variables holding functions
+func functionNames(env *Environment) []string {
+	seen := make(map[string]bool)
+	for _, b := range Builtins() {
+		seen[b.Name] = true
+	}
+	for name := range env.Functions {
+		seen[name] = true
+	}
+	for scope := env; scope != nil; scope = scope.Parent {
+		for name, value := range scope.Variables {
+			switch value.(type) {
+			case *FunctionValue, *HostFunction:
+				seen[name] = true
+			}
+		}
+	}
+	return sortedNames(seen)
+}
+
+// sortedNames returns the keys of a set in alphabetical order
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent letters that turn a into b
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	// rows[i][j] is the distance between the first i runes of s and the first j of t
+	rows := make([][]int, len(s)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(s)][len(t)]
+}
@@ -0,0 +1,373 @@
+package interpreter
+
+import (
+	"fmt"
+	"kwenda/ast"
+	"strconv"
+	"strings"
+)
+
+// Value is a Kwenda runtime value
+type Value interface {
+	Type() string   // Kwenda name of the value's type (namba, maneno, orodha, ...)
+	String() string // Text shown by andika and string concatenation
+}
+
+// NumberValue is an integer or floating-point number (namba)
+type NumberValue struct {
+	Int     int     // Value when IsFloat is false
+	Float   float64 // Value when IsFloat is true
+	IsFloat bool
+}
+
+// StringValue is a piece of text (maneno)
+type StringValue string
+
+// BoolValue is kweli or uwongo (boolean)
+type BoolValue bool
+
+// NilValue is the absence of a value (tupu)
+type NilValue struct{}
+
+// ArrayValue is a list of values (orodha). It is shared by reference, so
+// ongeza/ondoa and element assignment are seen by every holder of the array.
+type ArrayValue struct {
+	Elements []Value
+}
+
+// DictValue is a string-keyed map (kamusi) that remembers insertion order
+type DictValue struct {
+	Keys    []string
+	Entries map[string]Value
+}
+
+// ClassValue is a class definition (darasa)
+type ClassValue struct {
+	Definition ast.ClassNode
+}
+
+// InstanceValue is an object created with unda
+type InstanceValue struct {
+	Class  *ClassValue
+	Fields *DictValue
+}
+
+// FunctionValue is a callable lambda, with the environment it closes over
+type FunctionValue struct {
+	Name       string // Empty for lambdas
+	Parameters []ast.Parameter
+	ReturnType string
+	Body       []ast.ASTNode
+	Env        *Environment
+}
+
+// HostFunction is a function written in Go that a host program stores in a
+// variable, so scripts can call it by name
+type HostFunction struct {
+	Name string
+	Impl BuiltinFunc
+}
+
+// ErrorValue represents a runtime error
+type ErrorValue struct {
+	Message string
+	Context string  // Additional context about where the error occurred
+	Pos     ast.Pos // Source position where the error was raised
+}
+
+// Nil is the single nil value
+var Nil = NilValue{}
+
+// Int creates an integer number
+func Int(i int) NumberValue {
+	return NumberValue{Int: i}
+}
+
+// Float creates a floating-point number
+func Float(f float64) NumberValue {
+	return NumberValue{Float: f, IsFloat: true}
+}
+
+// NewArray creates an array holding the given elements
+func NewArray(elements []Value) *ArrayValue {
+	if elements == nil {
+		elements = []Value{}
+	}
+	return &ArrayValue{Elements: elements}
+}
+
+// NewDict creates an empty dictionary
+func NewDict() *DictValue {
+	return &DictValue{Entries: make(map[string]Value)}
+}
+
+func (n NumberValue) Type() string    { return "namba" }
+func (s StringValue) Type() string    { return "maneno" }
+func (b BoolValue) Type() string      { return "boolean" }
+func (NilValue) Type() string         { return "tupu" }
+func (a *ArrayValue) Type() string    { return "orodha" }
+func (d *DictValue) Type() string     { return "kamusi" }
+func (c *ClassValue) Type() string    { return "darasa" }
+func (i *InstanceValue) Type() string { return i.Class.Definition.Name }
+func (f *FunctionValue) Type() string { return "kazi" }
+func (h *HostFunction) Type() string  { return "kazi" }
+func (e ErrorValue) Type() string     { return "hitilafu" }
+
+// AsFloat returns the number as a float64 whatever its kind
+func (n NumberValue) AsFloat() float64 {
+	if n.IsFloat {
+		return n.Float
+	}
+	return float64(n.Int)
+}
+
+func (n NumberValue) String() string {
+	if n.IsFloat {
+		return fmt.Sprint(n.Float)
+	}
+	return strconv.Itoa(n.Int)
+}
+
+func (s StringValue) String() string {
+	return string(s)
+}
+
+func (b BoolValue) String() string {
+	return strconv.FormatBool(bool(b))
+}
+
+func (NilValue) String() string {
+	return "<nil>"
+}
+
+func (a *ArrayValue) String() string {
+	parts := make([]string, len(a.Elements))
+	for i, element := range a.Elements {
+		parts[i] = element.String()
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func (d *DictValue) String() string {
+	parts := make([]string, len(d.Keys))
+	for i, key := range d.Keys {
+		parts[i] = fmt.Sprintf("%q: %s", key, d.Entries[key])
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (c *ClassValue) String() string {
+	return "<darasa " + c.Definition.Name + ">"
+}
+
+func (i *InstanceValue) String() string {
+	return i.Class.Definition.Name + i.Fields.String()
+}
+
+func (f *FunctionValue) String() string {
+	if f.Name != "" {
+		return "<kazi " + f.Name + ">"
+	}
+	return "<lambda>"
+}
+
+func (h *HostFunction) String() string {
+	if h.Name != "" {
+		return "<kazi " + h.Name + ">"
+	}
+	return "<kazi>"
+}
+
+// String formats the error for display, prefixed with its source position when known
+func (e ErrorValue) String() string {
+	message := e.Message
+	if e.Context != "" {
+		message += " (" + e.Context + ")"
+	}
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + message
+	}
+	return message
+}
+
+// Error lets a thrown ErrorValue travel as a Go error until it is caught
+func (e ErrorValue) Error() string {
+	return e.String()
+}
+
+// Get returns the value stored under key
+func (d *DictValue) Get(key string) (Value, bool) {
+	value, exists := d.Entries[key]
+	return value, exists
+}
+
+// Set stores value under key, keeping the key's original position if it already exists
+func (d *DictValue) Set(key string, value Value) {
+	if _, exists := d.Entries[key]; !exists {
+		d.Keys = append(d.Keys, key)
+	}
+	d.Entries[key] = value
+}
+
+// toBool converts a value to boolean following Kwenda's rules
+func toBool(value Value) bool {
+	switch v := value.(type) {
+	case BoolValue:
+		return bool(v)
+	case NumberValue:
+		return v.AsFloat() != 0
+	case StringValue:
+		return v != ""
+	case NilValue, nil:
+		return false
+	default:
+		return true
+	}
+}
+
+// toNumber converts a value to a number; text is parsed, booleans become 1 or 0,
+// and anything else becomes 0
+func toNumber(value Value) NumberValue {
+	switch v := value.(type) {
+	case NumberValue:
+		return v
+	case StringValue:
+		text := string(v)
+		if i, err := strconv.Atoi(text); err == nil {
+			return Int(i)
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return Float(f)
+		}
+		return Int(0)
+	case BoolValue:
+		if v {
+			return Int(1)
+		}
+		return Int(0)
+	default:
+		return Int(0)
+	}
+}
+
+// valuesEqual compares two values for == and !=. Values of the same kind compare
+// directly (arrays, dictionaries and objects by identity), whole numbers equal the
+// same decimal numbers, and values of different kinds are never equal: "1" == 1
+// and kweli == 1 are false.
+func valuesEqual(left, right Value) bool {
+	switch l := left.(type) {
+	case NumberValue:
+		if r, ok := right.(NumberValue); ok {
+			return l.AsFloat() == r.AsFloat()
+		}
+	case StringValue:
+		if r, ok := right.(StringValue); ok {
+			return l == r
+		}
+	case BoolValue:
+		if r, ok := right.(BoolValue); ok {
+			return l == r
+		}
+	case NilValue:
+		_, ok := right.(NilValue)
+		return ok
+	case *ArrayValue, *DictValue, *InstanceValue, *FunctionValue, *HostFunction, *ClassValue, *ModuleValue:
+		return left == right
+	}
+	return false
+}
+
+// canCompare reports whether == and != can compare two values: any value with
+// tupu, numbers, text and booleans with each other, and arrays, dictionaries,
+// objects and functions with values of their own kind
+func canCompare(left, right Value) bool {
+	_, leftIsNil := left.(NilValue)
+	_, rightIsNil := right.(NilValue)
+	if leftIsNil || rightIsNil {
+		return true
+	}
+	leftIsScalar, rightIsScalar := isScalar(left), isScalar(right)
+	if leftIsScalar || rightIsScalar {
+		return leftIsScalar && rightIsScalar
+	}
+	_, leftIsInstance := left.(*InstanceValue)
+	_, rightIsInstance := right.(*InstanceValue)
+	return left.Type() == right.Type() || (leftIsInstance && rightIsInstance)
+}
+
+// isScalar reports whether a value is a number, text or boolean
+func isScalar(value Value) bool {
+	switch value.(type) {
+	case NumberValue, StringValue, BoolValue:
+		return true
+	}
+	return false
+}
+
+// getIndex reads container[index] for arrays, dictionaries and object fields;
+// missing entries and out-of-range indexes give nil
+func getIndex(container, index Value) Value {
+	switch c := container.(type) {
+	case *DictValue:
+		if value, exists := c.Get(index.String()); exists {
+			return value
+		}
+	case *InstanceValue:
+		if value, exists := c.Fields.Get(index.String()); exists {
+			return value
+		}
+	case *ArrayValue:
+		if idx, ok := index.(NumberValue); ok && !idx.IsFloat && idx.Int >= 0 && idx.Int < len(c.Elements) {
+			return c.Elements[idx.Int]
+		}
+	}
+	return Nil
+}
+
+// setIndex performs container[index] = value and returns the stored value, or nil
+// if nothing could be stored
+func setIndex(container, index, value Value) Value {
+	switch c := container.(type) {
+	case *DictValue:
+		c.Set(index.String(), value)
+		return value
+	case *InstanceValue:
+		c.Fields.Set(index.String(), value)
+		return value
+	case *ArrayValue:
+		if idx, ok := index.(NumberValue); ok && !idx.IsFloat && idx.Int >= 0 && idx.Int < len(c.Elements) {
+			c.Elements[idx.Int] = value
+			return value
+		}
+	}
+	return Nil
+}
+
+// getMember reads object.name from an object's fields or a dictionary
+func getMember(object Value, name string) Value {
+	switch o := object.(type) {
+	case *InstanceValue:
+		if value, exists := o.Fields.Get(name); exists {
+			return value
+		}
+	case *DictValue:
+		if value, exists := o.Get(name); exists {
+			return value
+		}
+	}
+	return Nil
+}
+
+// setMember performs object.name = value and returns the stored value, or nil
+// if object has no fields
+func setMember(object Value, name string, value Value) Value {
+	switch o := object.(type) {
+	case *InstanceValue:
+		o.Fields.Set(name, value)
+		return value
+	case *DictValue:
+		o.Set(name, value)
+		return value
+	}
+	return Nil
+}
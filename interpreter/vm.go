@@ -0,0 +1,421 @@
+package interpreter
+
+import (
+	"fmt"
+	"kwenda/ast"
+)
+
+// VM runs compiled bytecode. It shares Environment, values and built-ins with
+// the tree-walking interpreter and produces the same results; function bodies
+// are compiled the first time they are called.
+type VM struct {
+	bodies   map[*ast.ASTNode]*Code      // Compiled bodies, keyed by their first statement
+	defaults map[*ast.PropertyNode]*Code // Compiled property defaults
+}
+
+// NewVM creates a VM with an empty code cache
+func NewVM() *VM {
+	return &VM{
+		bodies:   make(map[*ast.ASTNode]*Code),
+		defaults: make(map[*ast.PropertyNode]*Code),
+	}
+}
+
+// handler is an error handler installed by OpPushHandler
+type handler struct {
+	target   int          // Where to continue after an error
+	stackLen int          // Stack height to restore
+	env      *Environment // Scope to restore
+}
+
+// boundMethod is a method looked up on an instance, waiting for its arguments
+type boundMethod struct {
+	instance *InstanceValue
+	method   *ast.FunctionNode
+}
+
+func (b *boundMethod) Type() string   { return "kazi" }
+func (b *boundMethod) String() string { return "<kazi " + b.method.Name + ">" }
+
+// Interpret runs a top-level node like the package-level Interpret, using bytecode
+func (vm *VM) Interpret(node ast.ASTNode, env *Environment) Value {
+	result, err := vm.Run(node, env)
+	if err != nil {
+		ReportError(env.Streams.Stderr, err)
+		return Nil
+	}
+	return result
+}
+
+// Run is like Interpret but returns an unhandled error instead of reporting it
+func (vm *VM) Run(node ast.ASTNode, env *Environment) (Value, error) {
+	var code *Code
+	var err error
+	if function, ok := node.(ast.FunctionNode); ok && function.Name == "kuu" {
+		code, err = vm.compiled(function.Body)
+	} else {
+		code, err = compileStatement(node)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vm.run(code, env)
+}
+
+// RunMain runs the body of kuu like the package-level RunMain, using bytecode
+func (vm *VM) RunMain(main ast.FunctionNode, env *Environment) (result Value, returned bool, err error) {
+	code, err := vm.compiled(main.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	return vm.execute(code, env)
+}
+
+// Call calls a function by name like the package-level Call, using bytecode
+func (vm *VM) Call(name string, args []Value, env *Environment) (Value, error) {
+	result, found, err := callByName(name, args, ast.Pos{}, env, vm.call)
+	if !found {
+		return nil, unknownFunction(name, ast.Pos{}, env)
+	}
+	return result, err
+}
+
+// compiled returns the code for a function body, compiling it on first use. A body
+// is identified by its backing array, which every copy of the AST node shares.
+func (vm *VM) compiled(body []ast.ASTNode) (*Code, error) {
+	if len(body) == 0 {
+		return CompileBody(body)
+	}
+	if code, exists := vm.bodies[&body[0]]; exists {
+		return code, nil
+	}
+	code, err := CompileBody(body)
+	if err != nil {
+		return nil, err
+	}
+	vm.bodies[&body[0]] = code
+	return code, nil
+}
+
+// call binds arguments to parameters in callEnv and runs the body
+func (vm *VM) call(parameters []ast.Parameter, body []ast.ASTNode, args []Value, callEnv *Environment) (Value, error) {
+	code, err := vm.compiled(body)
+	if err != nil {
+		return nil, err
+	}
+	for i, param := range parameters {
+		if i < len(args) {
+			callEnv.Set(param.Name, args[i])
+		}
+	}
+	return vm.run(code, callEnv)
+}
+
+// propertyDefault evaluates the default value of a class property
+func (vm *VM) propertyDefault(prop *ast.PropertyNode, env *Environment) (Value, error) {
+	code, exists := vm.defaults[prop]
+	if !exists {
+		var err error
+		if code, err = compileExpression(prop.Value); err != nil {
+			return nil, err
+		}
+		vm.defaults[prop] = code
+	}
+	return vm.run(code, env)
+}
+
+// run executes code in env until it returns or throws an error it does not handle
+func (vm *VM) run(code *Code, env *Environment) (Value, error) {
+	result, _, err := vm.execute(code, env)
+	return result, err
+}
+
+// execute is run that also reports whether the code returned through rudisha
+func (vm *VM) execute(code *Code, env *Environment) (Value, bool, error) {
+	instructions := code.Instructions
+	stack := make([]Value, 0, 16)
+	var handlers []handler
+	var result Value = Nil // Value of the last statement
+	var caught Value       // Error being handled
+	ip := 0
+
+	pop := func() Value {
+		value := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return value
+	}
+	// popN removes the top n values and returns a copy of them in order
+	popN := func(n int) []Value {
+		values := make([]Value, n)
+		copy(values, stack[len(stack)-n:])
+		stack = stack[:len(stack)-n]
+		return values
+	}
+
+	for {
+		op := Opcode(instructions[ip])
+		pos := code.Positions[ip]
+		a, b := 0, 0
+		switch operandCounts[op] {
+		case 2:
+			b = int(instructions[ip+3])<<8 | int(instructions[ip+4])
+			fallthrough
+		case 1:
+			a = int(instructions[ip+1])<<8 | int(instructions[ip+2])
+		}
+		ip += 1 + 2*operandCounts[op]
+
+		var err error
+		switch op {
+		case OpConstant:
+			stack = append(stack, code.Constants[a])
+
+		case OpNil:
+			stack = append(stack, Nil)
+
+		case OpPop:
+			pop()
+
+		case OpPopResult:
+			result = pop()
+
+		case OpClearResult:
+			result = Nil
+
+		case OpSaveResult:
+			stack = append(stack, result)
+
+		case OpRestoreResult:
+			result = pop()
+
+		case OpLoad:
+			var value Value
+			if value, err = lookupName(code.Names[a], pos, env); err == nil {
+				stack = append(stack, value)
+			}
+
+		case OpStore:
+			env.Set(code.Names[a], stack[len(stack)-1])
+
+		case OpThis:
+			var value Value
+			if value, err = lookupThis(pos, env); err == nil {
+				stack = append(stack, value)
+			}
+
+		case OpBinary:
+			right := pop()
+			left := pop()
+			var value Value
+			if value, err = binaryOp(code.Names[a], left, right, pos); err == nil {
+				stack = append(stack, value)
+			}
+
+		case OpUnary:
+			var value Value
+			if value, err = unaryOp(code.Names[a], pop(), pos); err == nil {
+				stack = append(stack, value)
+			}
+
+		case OpJump:
+			ip = a
+
+		case OpJumpIfFalse:
+			if !toBool(pop()) {
+				ip = a
+			}
+
+		case OpArray:
+			stack = append(stack, NewArray(popN(a)))
+
+		case OpDict:
+			pairs := popN(2 * a)
+			dict := NewDict()
+			for i := 0; i < len(pairs); i += 2 {
+				dict.Set(pairs[i].String(), pairs[i+1])
+			}
+			stack = append(stack, dict)
+
+		case OpIndex:
+			index := pop()
+			container := pop()
+			stack = append(stack, getIndex(container, index))
+
+		case OpSetIndex:
+			value := pop()
+			index := pop()
+			container := pop()
+			stack = append(stack, setIndex(container, index, value))
+
+		case OpUpdateIndex:
+			value := pop()
+			index := pop()
+			container := pop()
+			if value, err = binaryOp(code.Names[a], getIndex(container, index), value, pos); err == nil {
+				stack = append(stack, setIndex(container, index, value))
+			}
+
+		case OpMember:
+			var value Value
+			if value, err = memberOf(pop(), code.Names[a], pos); err == nil {
+				stack = append(stack, value)
+			}
+
+		case OpSetMember:
+			value := pop()
+			object := pop()
+			stack = append(stack, setMember(object, code.Names[a], value))
+
+		case OpUpdateMember:
+			value := pop()
+			object := pop()
+			if value, err = binaryOp(code.Names[b], getMember(object, code.Names[a]), value, pos); err == nil {
+				stack = append(stack, setMember(object, code.Names[a], value))
+			}
+
+		case OpCall:
+			value, found, callErr := callByName(code.Names[a], popN(b), pos, env, vm.call)
+			if !found {
+				value, callErr = missingFunction(code.Names[a], pos, env)
+			}
+			if err = callErr; err == nil {
+				stack = append(stack, value)
+			}
+
+		case OpGetMethod:
+			object := pop()
+			if module, ok := object.(*ModuleValue); ok {
+				// A module's function is called like a lambda
+				var callee Value
+				if callee, err = module.Member(code.Names[a], pos); err == nil {
+					stack = append(stack, callee)
+				}
+				break
+			}
+			if callee, ok := fieldFunction(object, code.Names[a], env); ok {
+				// A function stored in a field or dictionary is called like a lambda
+				stack = append(stack, callee)
+				break
+			}
+			var instance *InstanceValue
+			var method *ast.FunctionNode
+			if instance, method, err = lookupMethod(object, code.Names[a], pos, env); err == nil {
+				stack = append(stack, &boundMethod{instance: instance, method: method})
+			}
+
+		case OpCallMethod:
+			args := popN(a)
+			var value Value
+			if bound, ok := stack[len(stack)-1].(*boundMethod); ok {
+				pop()
+				methodEnv := NewChildEnvironment(env)
+				methodEnv.Set("hii", bound.instance)
+				value, err = vm.call(bound.method.Parameters, bound.method.Body, args, methodEnv)
+			} else {
+				value, err = callValue(pop(), "", args, pos, env, vm.call)
+			}
+			if err == nil {
+				stack = append(stack, value)
+			}
+
+		case OpNew:
+			var class ast.ClassNode
+			if class, err = lookupClass(code.Names[a], pos, env); err != nil {
+				break
+			}
+			var instance *InstanceValue
+			instance, err = instantiate(class, env, func(prop *ast.PropertyNode) (Value, error) {
+				return vm.propertyDefault(prop, env)
+			})
+			if err == nil {
+				stack = append(stack, instance)
+			}
+
+		case OpConstruct:
+			args := popN(a)
+			instance := stack[len(stack)-1].(*InstanceValue)
+			if constructor := instance.Class.Definition.Constructor; constructor != nil {
+				constructorEnv := NewChildEnvironment(env)
+				constructorEnv.Set("hii", instance)
+				_, err = vm.call(constructor.Parameters, constructor.Body, args, constructorEnv)
+			}
+
+		case OpClosure:
+			lambda := code.Lambdas[a]
+			stack = append(stack, &FunctionValue{
+				Parameters: lambda.Parameters,
+				ReturnType: lambda.ReturnType,
+				Body:       lambda.Body,
+				Env:        env,
+			})
+
+		case OpReturn:
+			return pop(), a == 1, nil
+
+		case OpThrow:
+			err = thrownError(pop(), pos)
+
+		case OpIterate:
+			var it *iterator
+			if it, err = iterate(pop(), a, pos); err == nil {
+				stack = append(stack, it)
+			}
+
+		case OpNext:
+			it := stack[len(stack)-1].(*iterator)
+			key, value, ok := it.step()
+			if !ok {
+				ip = a
+				break
+			}
+			if it.pairs {
+				stack = append(stack, key)
+			}
+			stack = append(stack, value)
+
+		case OpMatch:
+			var matched bool
+			if matched, err = matchArm(code.Arms[a], stack[len(stack)-1], env); err == nil {
+				stack = append(stack, BoolValue(matched))
+			}
+
+		case OpPushHandler:
+			handlers = append(handlers, handler{target: a, stackLen: len(stack), env: env})
+
+		case OpPopHandler:
+			handlers = handlers[:len(handlers)-1]
+
+		case OpCaught:
+			stack = append(stack, caught)
+
+		case OpPushScope:
+			env = NewChildEnvironment(env)
+
+		case OpPopScope:
+			env = env.Parent
+
+		case OpDeclare:
+			switch declaration := code.Declarations[a].(type) {
+			case ast.FunctionNode:
+				env.SetFunction(declaration.Name, declaration)
+			case ast.ClassNode:
+				env.SetClass(declaration.Name, declaration)
+			}
+
+		default:
+			return nil, false, fmt.Errorf("unknown opcode %s", op)
+		}
+
+		if err != nil {
+			if len(handlers) == 0 {
+				return nil, false, err
+			}
+			h := handlers[len(handlers)-1]
+			handlers = handlers[:len(handlers)-1]
+			stack = stack[:h.stackLen]
+			env = h.env
+			caught = asErrorValue(err)
+			ip = h.target
+		}
+	}
+}
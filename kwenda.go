@@ -0,0 +1,315 @@
+// Package kwenda runs Kwenda programs from Go. A Runtime holds one program's
+// global scope, loaded modules, host functions and input/output; runtimes share
+// nothing, so several can run at the same time.
+//
+//	rt := kwenda.NewRuntime()
+//	rt.SetStdout(&out)
+//	rt.Register("saa", func(args ...any) (any, error) { return time.Now().Hour(), nil })
+//	if err := rt.RunFile("programu.swh"); err != nil { ... }
+//	jumla, err := rt.Call("jumla", 2, 3)
+package kwenda
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"kwenda/ast"
+	"kwenda/interpreter"
+	"kwenda/lexer"
+	"kwenda/parser"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Runtime runs Kwenda code. Each runtime is isolated from the others, but a
+// single runtime must not be used from several goroutines at once.
+type Runtime struct {
+	env     *interpreter.Environment
+	vm      *interpreter.VM                     // Bytecode VM, or nil for the tree-walking interpreter
+	modules map[string]*interpreter.ModuleValue // Loaded modules by absolute path
+	args    []string                            // Command-line arguments for the program (hoja)
+
+	modulePath []string // Directories searched for imports after the importing file's own
+	stdlib     fs.FS    // Standard library modules, or nil
+	loading    []string // Files whose imports are being loaded, outermost first
+}
+
+// SyntaxError is returned when source code cannot be parsed
+type SyntaxError struct {
+	Errors []parser.Error
+}
+
+// Error lists the syntax errors under a bilingual heading, one per line
+func (e *SyntaxError) Error() string {
+	lines := []string{fmt.Sprintf("Hitilafu za sintaksia (syntax errors): %d", len(e.Errors))}
+	for _, err := range e.Errors {
+		lines = append(lines, "  "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NewRuntime creates a runtime that reads os.Stdin and writes to os.Stdout and os.Stderr
+func NewRuntime() *Runtime {
+	r := &Runtime{
+		env:        interpreter.NewEnvironment(),
+		modules:    make(map[string]*interpreter.ModuleValue),
+		modulePath: filepath.SplitList(os.Getenv("KWENDA_PATH")),
+		stdlib:     defaultStdlib(),
+	}
+	r.SetArgs(nil)
+	return r
+}
+
+// UseVM runs code on the bytecode VM instead of the tree-walking interpreter
+func (r *Runtime) UseVM() {
+	r.vm = interpreter.NewVM()
+}
+
+// SetStdin sets where ingiza reads input from
+func (r *Runtime) SetStdin(in io.Reader) {
+	r.env.Streams.Stdin = in
+}
+
+// SetStdout sets where andika writes output
+func (r *Runtime) SetStdout(out io.Writer) {
+	r.env.Streams.Stdout = out
+}
+
+// SetStderr sets where warnings and unhandled errors are written
+func (r *Runtime) SetStderr(out io.Writer) {
+	r.env.Streams.Stderr = out
+}
+
+// SetLegacy turns legacy mode on or off. In legacy mode an undefined name
+// evaluates to its own name as text and calling an unknown function gives tupu,
+// as in early versions of Kwenda; otherwise both are errors that jaribu can catch.
+func (r *Runtime) SetLegacy(legacy bool) {
+	r.env.Options.Legacy = legacy
+}
+
+// SetArgs sets the command-line arguments the program receives, as the global
+// orodha hoja and as the parameter of kuu when it declares one
+func (r *Runtime) SetArgs(args []string) {
+	r.args = args
+	r.env.Set("hoja", r.argsValue())
+}
+
+// argsValue returns a fresh orodha of the program's arguments, so a program that
+// changes one copy does not change the other
+func (r *Runtime) argsValue() *interpreter.ArrayValue {
+	elements := make([]interpreter.Value, len(r.args))
+	for i, arg := range r.args {
+		elements[i] = interpreter.StringValue(arg)
+	}
+	return interpreter.NewArray(elements)
+}
+
+// Reset forgets every variable, function, class and module, keeping the
+// runtime's input, output, options, arguments and engine
+func (r *Runtime) Reset() {
+	streams, options := r.env.Streams, r.env.Options
+	r.env = interpreter.NewEnvironment()
+	r.env.Streams = streams
+	r.env.Options = options
+	r.SetArgs(r.args)
+	r.modules = make(map[string]*interpreter.ModuleValue)
+	if r.vm != nil {
+		r.vm = interpreter.NewVM()
+	}
+}
+
+// Environment returns the runtime's global scope
+func (r *Runtime) Environment() *interpreter.Environment {
+	return r.env
+}
+
+// RunFile runs the program in a .swh file
+func (r *Runtime) RunFile(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	_, _, err = r.run(path, string(source))
+	return err
+}
+
+// RunString runs a program given as source code
+func (r *Runtime) RunString(source string) error {
+	_, _, err := r.run("", source)
+	return err
+}
+
+// run parses and runs source read from filename
+func (r *Runtime) run(filename, source string) (interpreter.Value, bool, error) {
+	program := parser.ParseProgram(lexer.LexFile(filename, source))
+	if len(program.Errors) > 0 {
+		return nil, false, &SyntaxError{Errors: program.Errors}
+	}
+	return r.RunProgram(program)
+}
+
+// RunProgram runs a parsed program: it loads its imports, records its functions,
+// classes and global variables, and then runs kuu if there is one, passing it the
+// program's arguments if it takes a parameter. returned reports whether kuu
+// ended with rudisha, in which case result is the value it returned.
+func (r *Runtime) RunProgram(program parser.ProgramNode) (result interpreter.Value, returned bool, err error) {
+	if err := r.loadImports(program.Imports, r.env); err != nil {
+		return nil, false, err
+	}
+
+	// kuu runs last, so it can use everything declared after it
+	var main *ast.FunctionNode
+	for _, node := range program.Functions {
+		if function, ok := node.(ast.FunctionNode); ok && function.Name == "kuu" {
+			r.env.SetFunction(function.Name, function)
+			main = &function
+			continue
+		}
+		if _, err := r.exec(node, r.env); err != nil {
+			return nil, false, err
+		}
+	}
+	if main != nil {
+		if err := r.bindArgs(*main); err != nil {
+			return nil, false, err
+		}
+		if r.vm != nil {
+			return r.vm.RunMain(*main, r.env)
+		}
+		return interpreter.RunMain(*main, r.env)
+	}
+	return interpreter.Nil, false, nil
+}
+
+// bindArgs gives kuu(orodha maneno hoja) its parameter. kuu runs in the global
+// scope, so the parameter is a global variable under the name kuu chose.
+func (r *Runtime) bindArgs(main ast.FunctionNode) error {
+	switch len(main.Parameters) {
+	case 0:
+		return nil
+	case 1:
+		param := main.Parameters[0]
+		if param.Type != "" && param.Type != "orodha" && param.Type != "orodha maneno" {
+			return interpreter.ErrorValue{
+				Message: fmt.Sprintf("Kigezo '%s' cha kuu lazima kiwe orodha maneno", param.Name),
+				Context: fmt.Sprintf("The parameter '%s' of kuu receives the command-line arguments, so it must be orodha maneno", param.Name),
+				Pos:     param.Pos,
+			}
+		}
+		r.env.Set(param.Name, r.argsValue())
+		return nil
+	default:
+		return interpreter.ErrorValue{
+			Message: fmt.Sprintf("kuu inaweza kupokea kigezo kimoja tu, lakini ina %d", len(main.Parameters)),
+			Context: "kuu takes no parameters, or one orodha maneno holding the command-line arguments",
+			Pos:     main.Parameters[1].Pos,
+		}
+	}
+}
+
+// Eval runs a piece of code the way the REPL does: any statement may appear at the
+// top level, declarations stay in the runtime for later calls, and a function
+// named kuu is recorded rather than run. When the last statement is an expression
+// its value is returned; otherwise the result is tupu.
+func (r *Runtime) Eval(source string) (interpreter.Value, error) {
+	program := parser.ParseInteractive(lexer.Lex(source))
+	if len(program.Errors) > 0 {
+		return nil, &SyntaxError{Errors: program.Errors}
+	}
+	if err := r.loadImports(program.Imports, r.env); err != nil {
+		return nil, err
+	}
+
+	var result interpreter.Value = interpreter.Nil
+	for _, node := range program.Functions {
+		if function, ok := node.(ast.FunctionNode); ok {
+			r.env.SetFunction(function.Name, function)
+			result = interpreter.Nil
+			continue
+		}
+		value, err := r.exec(node, r.env)
+		if err != nil {
+			return nil, err
+		}
+		result = interpreter.Nil
+		if isExpression(node) {
+			result = value
+		}
+	}
+	return result, nil
+}
+
+// isExpression reports whether a top-level node is an expression whose value is
+// worth showing, rather than a declaration, assignment or control statement
+func isExpression(node ast.ASTNode) bool {
+	switch node.(type) {
+	case ast.VariableDeclarationNode, ast.StringVariableDeclarationNode, ast.ArrayDeclarationNode,
+		ast.ArrayAssignmentNode, ast.DictionaryDeclarationNode, ast.DictionaryAssignmentNode,
+		ast.ClassVariableDeclarationNode, ast.MemberAssignmentNode, ast.ClassNode,
+		ast.IfNode, ast.WhileNode, ast.ForNode, ast.ForEachNode, ast.MatchNode, ast.TryNode, ast.ThrowNode,
+		ast.ReturnNode, ast.BreakNode, ast.ContinueNode:
+		return false
+	}
+	return true
+}
+
+// exec runs a top-level node on the runtime's engine
+func (r *Runtime) exec(node ast.ASTNode, env *interpreter.Environment) (interpreter.Value, error) {
+	if r.vm != nil {
+		return r.vm.Run(node, env)
+	}
+	return interpreter.Run(node, env)
+}
+
+// Call calls a Kwenda function, lambda or built-in by name with Go arguments and
+// returns its result as a Go value
+func (r *Runtime) Call(name string, args ...any) (any, error) {
+	values := make([]interpreter.Value, len(args))
+	for i, arg := range args {
+		value, err := ToValue(arg)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	var result interpreter.Value
+	var err error
+	if r.vm != nil {
+		result, err = r.vm.Call(name, values, r.env)
+	} else {
+		result, err = interpreter.Call(name, values, r.env)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return FromValue(result), nil
+}
+
+// Register makes a Go function callable from Kwenda code by name. Arguments and
+// the result are converted with FromValue and ToValue, and a returned error is
+// thrown as a Kwenda error that jaribu can catch. The function hides a built-in
+// with the same name, as the program's own functions do.
+func (r *Runtime) Register(name string, fn func(args ...any) (any, error)) {
+	r.env.Set(name, hostFunction(name, fn))
+}
+
+// Set stores a Go value in a global variable
+func (r *Runtime) Set(name string, value any) error {
+	converted, err := ToValue(value)
+	if err != nil {
+		return err
+	}
+	r.env.Set(name, converted)
+	return nil
+}
+
+// Get returns the value of a global variable as a Go value
+func (r *Runtime) Get(name string) (any, bool) {
+	value, exists := r.env.Variables[name]
+	if !exists {
+		return nil, false
+	}
+	return FromValue(value), true
+}
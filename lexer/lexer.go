@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -15,35 +16,41 @@ const (
 	TokenPunctuation TokenType = "PUNCTUATION"
 	TokenString     TokenType = "STRING"
 	TokenBoolean    TokenType = "BOOLEAN"
+	// TokenStringPart is the text of a string up to an interpolation ("Habari ${");
+	// the tokens of the interpolated expression follow it, then the rest of the string
+	TokenStringPart TokenType = "STRING_PART"
+	// TokenError reports malformed input; Value holds the bilingual message
+	TokenError TokenType = "ERROR"
 )
 
 type Token struct {
-	Type  TokenType
-	Value string
-	Line  int // Line number where token appears
+	Type   TokenType
+	Value  string
+	File   string // Source file the token came from (empty when unknown)
+	Line   int    // Line number where token appears (1-based)
+	Column int    // Column where token starts (1-based, counted in runes)
 }
 
+// isSwahiliKeyword reports whether word is reserved syntax. Built-in functions such
+// as andika are ordinary identifiers; the interpreter keeps them in a registry.
 func isSwahiliKeyword(word string) bool {
 	keywords := []string{
-		"kazi", "kama", "sivyo", "kwa", "wakati", "rudisha", "namba", "andika", "ingiza",
-		"kweli", "uwongo", "na", "au", "vunja", "endelea", "boolean", "maneno",
-		// Array keywords
-		"orodha", "ongeza", "ondoa", "urefu_orodha", "pata",
-		// File I/O keywords
-		"soma", "andika_faili", "unda_faili", "faili_ipo", "ondoa_faili",
+		"kazi", "kama", "sivyo", "kwa", "wakati", "rudisha", "namba", "ingiza",
+		"kweli", "uwongo", "na", "au", "si", "vunja", "endelea", "boolean", "maneno",
+		// Array keyword
+		"orodha",
 		// Import/Module keywords
 		"leta", "kutoka", "moduli", "umma",
 		// Error handling keywords
 		"jaribu", "shika", "hatimaye", "tupa",
-		// String manipulation functions
-		"unganisha", "kata", "badilisha", "tafuta", "awali", "mwisho",
-		"herufi_kubwa", "herufi_ndogo", "ondoa_nafasi", "gawanya_maneno",
 		// OOP keywords
 		"darasa", "unda", "hii",
 		// Dictionary/Map keywords
 		"kamusi",
 		// Lambda/Anonymous function keyword
 		"lambda",
+		// Pattern matching keyword
+		"chagua",
 	}
 	for _, kw := range keywords {
 		if kw == word {
@@ -71,147 +78,213 @@ func isNumber(word string) bool {
 	return true
 }
 
-// Helper function to create token with line number
-func makeToken(tokenType TokenType, value string, line int) Token {
-	return Token{Type: tokenType, Value: value, Line: line}
+// classifyWord returns the token type of a completed word
+func classifyWord(word string) TokenType {
+	if isSwahiliKeyword(word) {
+		return TokenKeyword
+	}
+	if isNumber(word) {
+		return TokenNumber
+	}
+	return TokenIdentifier
 }
 
+// escapeSequence decodes the escape starting after a backslash at runes[i].
+// It returns the decoded text, the number of runes consumed after the backslash,
+// and false if the escape is not valid.
+func escapeSequence(runes []rune, i int) (string, int, bool) {
+	if i >= len(runes) {
+		return "", 0, false
+	}
+	switch runes[i] {
+	case 'n':
+		return "\n", 1, true
+	case 't':
+		return "\t", 1, true
+	case 'r':
+		return "\r", 1, true
+	case '0':
+		return "\x00", 1, true
+	case '\\', '"', '\'', '$':
+		return string(runes[i]), 1, true
+	case 'u':
+		// Unicode escapes: \u00E9 or \u{1F600}
+		digits, consumed := "", 1
+		if i+1 < len(runes) && runes[i+1] == '{' {
+			end := i + 2
+			for end < len(runes) && runes[end] != '}' && runes[end] != '\n' {
+				end++
+			}
+			if end >= len(runes) || runes[end] != '}' {
+				return "", 0, false
+			}
+			digits = string(runes[i+2 : end])
+			consumed = end - i + 1
+		} else if i+4 < len(runes) {
+			digits = string(runes[i+1 : i+5])
+			consumed = 5
+		}
+		code, err := strconv.ParseUint(digits, 16, 32)
+		if digits == "" || len(digits) > 6 || err != nil || code > unicode.MaxRune {
+			return "", 0, false
+		}
+		return string(rune(code)), consumed, true
+	}
+	return "", 0, false
+}
+
+// Lex converts source code into tokens
 func Lex(input string) []Token {
+	return LexFile("", input)
+}
+
+// LexFile converts source code into tokens, recording filename in each token's position
+func LexFile(filename string, input string) []Token {
 	var tokens []Token
 	var currentToken strings.Builder
 	var inString bool
 	runes := []rune(input)
 	lineNumber := 1 // Track current line number
+	column := 0     // Column of the current character
+	startLine, startColumn := 0, 0 // Where the current word or string started
+	quoteLine, quoteColumn := 0, 0 // Where the current string's opening quote is
+	// Open brace count of each ${ ... } we are inside; the matching } resumes the string
+	var interpolations []int
+
+	// Helper function to create token with position
+	makeToken := func(tokenType TokenType, value string, line int, col int) Token {
+		return Token{Type: tokenType, Value: value, File: filename, Line: line, Column: col}
+	}
+
+	// lexError records a diagnostic as an error token
+	lexError := func(message string, line int, col int) {
+		tokens = append(tokens, makeToken(TokenError, message, line, col))
+	}
+
+	// flushWord finalizes the word being built, if any
+	flushWord := func() {
+		if currentToken.Len() > 0 {
+			tokenValue := currentToken.String()
+			tokens = append(tokens, makeToken(classifyWord(tokenValue), tokenValue, startLine, startColumn))
+			currentToken.Reset()
+		}
+	}
 
 	for i := 0; i < len(runes); i++ {
 		char := runes[i]
-		
-		// Track line numbers
-		if char == '\n' {
-			lineNumber++
-		}
-		
-		if char == '"' {
+		column++
+
+		if inString && char == '\\' {
+			// Escape sequences (\n, \t, \", \\, \$, \u00E9, \u{1F600})
+			text, consumed, ok := escapeSequence(runes, i+1)
+			if !ok {
+				lexError("Mfuatano wa kutoroka si sahihi katika maneno (invalid escape sequence in string)", lineNumber, column)
+				continue
+			}
+			currentToken.WriteString(text)
+			i += consumed
+			column += consumed
+		} else if inString && char == '$' && i+1 < len(runes) && runes[i+1] == '{' {
+			// Start of an interpolation ("Habari ${jina}")
+			tokens = append(tokens, makeToken(TokenStringPart, currentToken.String(), startLine, startColumn))
+			currentToken.Reset()
+			inString = false
+			interpolations = append(interpolations, 0)
+			i++
+			column++
+		} else if inString && char == '\n' {
+			// Strings end on the line they start
+			lexError("Maneno hayajafungwa kwa '\"' (unterminated string)", quoteLine, quoteColumn)
+			tokens = append(tokens, makeToken(TokenString, currentToken.String(), startLine, startColumn))
+			currentToken.Reset()
+			inString = false
+			interpolations = nil
+		} else if char == '"' {
 			// Handle string literals
 			if inString {
-				tokens = append(tokens, makeToken(TokenString, currentToken.String(), lineNumber))
+				tokens = append(tokens, makeToken(TokenString, currentToken.String(), startLine, startColumn))
 				currentToken.Reset()
 				inString = false
 			} else {
+				flushWord()
 				inString = true
+				startLine, startColumn = lineNumber, column
+				quoteLine, quoteColumn = lineNumber, column
 			}
 		} else if inString {
 			currentToken.WriteRune(char)
+		} else if char == '}' && len(interpolations) > 0 && interpolations[len(interpolations)-1] == 0 {
+			// End of an interpolation; the string continues after the brace
+			flushWord()
+			interpolations = interpolations[:len(interpolations)-1]
+			inString = true
+			startLine, startColumn = lineNumber, column+1
 		} else if unicode.IsSpace(char) {
 			// End of current token
-			if currentToken.Len() > 0 {
-				tokenValue := currentToken.String()
-				if isSwahiliKeyword(tokenValue) {
-					tokens = append(tokens, Token{Type: TokenKeyword, Value: tokenValue})
-				} else if isNumber(tokenValue) {
-					tokens = append(tokens, Token{Type: TokenNumber, Value: tokenValue})
-				} else if tokenValue == "kweli" || tokenValue == "uwongo" {
-					tokens = append(tokens, Token{Type: TokenBoolean, Value: tokenValue})
-				} else {
-					tokens = append(tokens, Token{Type: TokenIdentifier, Value: tokenValue})
-				}
-				currentToken.Reset()
-			}
-		} else if char == '+' || char == '-' || char == '*' || char == '/' || char == '=' || char == '!' || char == '<' || char == '>' {
+			flushWord()
+		} else if char == '+' || char == '-' || char == '*' || char == '/' || char == '%' || char == '=' || char == '!' || char == '<' || char == '>' {
 			// Handle operators and comparisons
-			if currentToken.Len() > 0 {
-				tokenValue := currentToken.String()
-				if isSwahiliKeyword(tokenValue) {
-					tokens = append(tokens, Token{Type: TokenKeyword, Value: tokenValue})
-				} else if isNumber(tokenValue) {
-					tokens = append(tokens, Token{Type: TokenNumber, Value: tokenValue})
-				} else {
-					tokens = append(tokens, Token{Type: TokenIdentifier, Value: tokenValue})
-				}
-				currentToken.Reset()
-			}
-			
-			// Handle multi-character operators like ==, !=, <=, >=
-			if i+1 < len(runes) && runes[i+1] == '=' {
-				if char == '=' {
-					tokens = append(tokens, Token{Type: TokenOperator, Value: "=="})
-				} else if char == '!' {
-					tokens = append(tokens, Token{Type: TokenOperator, Value: "!="})
-				} else if char == '<' {
-					tokens = append(tokens, Token{Type: TokenOperator, Value: "<="})
-				} else if char == '>' {
-					tokens = append(tokens, Token{Type: TokenOperator, Value: ">="})
+			flushWord()
+
+			if char == '*' && i+1 < len(runes) && runes[i+1] == '*' {
+				// Handle the power operator ** and its compound assignment **=
+				op := "**"
+				if i+2 < len(runes) && runes[i+2] == '=' {
+					op = "**="
 				}
+				tokens = append(tokens, makeToken(TokenOperator, op, lineNumber, column))
+				i += len(op) - 1
+				column += len(op) - 1
+			} else if i+1 < len(runes) && runes[i+1] == '=' {
+				// Handle multi-character operators like ==, !=, <=, >= and compound assignments like +=
+				tokens = append(tokens, makeToken(TokenOperator, string(char)+"=", lineNumber, column))
 				// Skip the next character since we consumed it
 				i++
+				column++
 			} else {
-				tokens = append(tokens, Token{Type: TokenOperator, Value: string(char)})
+				tokens = append(tokens, makeToken(TokenOperator, string(char), lineNumber, column))
 			}
+		} else if char == '.' && isNumber(currentToken.String()) && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) {
+			// Decimal point inside a number literal (e.g., 3.14)
+			currentToken.WriteRune(char)
 		} else if char == '{' || char == '}' || char == '(' || char == ')' || char == '[' || char == ']' || char == ';' || char == ',' || char == ':' || char == '.' {
 			// Handle punctuation
-			if currentToken.Len() > 0 {
-				tokenValue := currentToken.String()
-				if isSwahiliKeyword(tokenValue) {
-					tokens = append(tokens, Token{Type: TokenKeyword, Value: tokenValue})
-				} else if isNumber(tokenValue) {
-					tokens = append(tokens, Token{Type: TokenNumber, Value: tokenValue})
-				} else {
-					tokens = append(tokens, Token{Type: TokenIdentifier, Value: tokenValue})
-				}
-				currentToken.Reset()
+			flushWord()
+			if len(interpolations) > 0 && char == '{' {
+				interpolations[len(interpolations)-1]++
+			} else if len(interpolations) > 0 && char == '}' {
+				interpolations[len(interpolations)-1]--
 			}
-			tokens = append(tokens, Token{Type: TokenPunctuation, Value: string(char)})
+			tokens = append(tokens, makeToken(TokenPunctuation, string(char), lineNumber, column))
 		} else if char == '#' {
 			// Handle comments (ignore the rest of the line)
 			// First, finalize any current token
-			if currentToken.Len() > 0 {
-				tokenValue := currentToken.String()
-				if isSwahiliKeyword(tokenValue) {
-					tokens = append(tokens, Token{Type: TokenKeyword, Value: tokenValue})
-				} else if isNumber(tokenValue) {
-					tokens = append(tokens, Token{Type: TokenNumber, Value: tokenValue})
-				} else {
-					tokens = append(tokens, Token{Type: TokenIdentifier, Value: tokenValue})
-				}
-				currentToken.Reset()
-			}
+			flushWord()
 			// Skip the rest of the line
 			for i+1 < len(runes) && runes[i+1] != '\n' {
 				i++
 			}
-		} else if char == '.' {
-			// Handle decimal point - could be part of a number or module access
-			if currentToken.Len() > 0 {
-				tokenValue := currentToken.String()
-				// If current token is a number and next char is a digit, it's a decimal
-				if unicode.IsDigit(rune(tokenValue[0])) && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) {
-					currentToken.WriteRune(char)
-				} else {
-					// Otherwise, it's module access - keep dot with identifier
-					currentToken.WriteRune(char)
-				}
-			} else {
-				currentToken.WriteRune(char)
-			}
 		} else {
 			// Build the current token
+			if currentToken.Len() == 0 {
+				startLine, startColumn = lineNumber, column
+			}
 			currentToken.WriteRune(char)
 		}
+
+		// Track line numbers
+		if char == '\n' {
+			lineNumber++
+			column = 0
+		}
 	}
 
 	// Handle the last token if any
-	if currentToken.Len() > 0 {
-		tokenValue := currentToken.String()
-		if isSwahiliKeyword(tokenValue) {
-			tokens = append(tokens, Token{Type: TokenKeyword, Value: tokenValue})
-		} else if isNumber(tokenValue) {
-			tokens = append(tokens, Token{Type: TokenNumber, Value: tokenValue})
-		} else if tokenValue == "kweli" || tokenValue == "uwongo" {
-			tokens = append(tokens, Token{Type: TokenBoolean, Value: tokenValue})
-		} else {
-			tokens = append(tokens, Token{Type: TokenIdentifier, Value: tokenValue})
-		}
+	if inString || len(interpolations) > 0 {
+		lexError("Maneno hayajafungwa kwa '\"' (unterminated string)", quoteLine, quoteColumn)
+		currentToken.Reset()
 	}
+	flushWord()
 
 	return tokens
-}
\ No newline at end of file
+}
@@ -0,0 +1,306 @@
+package kwenda
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"kwenda/ast"
+	"kwenda/interpreter"
+	"kwenda/lexer"
+	"kwenda/parser"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// stdlibPrefix marks standard library files in module keys and error positions
+const stdlibPrefix = "<stdlib>/"
+
+//go:embed modules/*.swh
+var stdlibFiles embed.FS
+
+// stdlibNames maps the Swahili short names of standard library modules to their
+// files; the English names (leta "math") are the files without .swh. A short name
+// becomes a namespace, so it cannot be a reserved word such as maneno or orodha.
+var stdlibNames = map[string]string{
+	"hesabu":    "math.swh",
+	"maandishi": "strings.swh",
+	"safu":      "arrays.swh",
+}
+
+// defaultStdlib is the standard library built into the executable
+func defaultStdlib() fs.FS {
+	fsys, err := fs.Sub(stdlibFiles, "modules")
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}
+
+// StdlibModule describes a module of the standard library
+type StdlibModule struct {
+	Name    string   // Short name to import it with (leta "hesabu")
+	File    string   // File in the standard library
+	Exports []string // What the module exports, written as declarations
+}
+
+// Stdlib lists the modules built into the standard library
+func Stdlib() []StdlibModule {
+	files := make(map[string]string) // File to short name
+	for name, file := range stdlibNames {
+		files[file] = name
+	}
+
+	var modules []StdlibModule
+	entries, _ := fs.ReadDir(defaultStdlib(), ".")
+	for _, entry := range entries {
+		source, err := fs.ReadFile(defaultStdlib(), entry.Name())
+		if err != nil {
+			continue
+		}
+		name, ok := files[entry.Name()]
+		if !ok {
+			name = strings.TrimSuffix(entry.Name(), ".swh")
+		}
+		program := parser.ParseProgram(lexer.LexFile(entry.Name(), string(source)))
+		modules = append(modules, StdlibModule{Name: name, File: entry.Name(), Exports: describeExports(program)})
+	}
+	return modules
+}
+
+// describeExports writes out the declarations a module exports, starting with
+// those of its native half
+func describeExports(program parser.ProgramNode) []string {
+	exported := make(map[string]bool)
+	for _, name := range program.Module.Exports {
+		exported[name] = true
+	}
+
+	var exports []string
+	if native, exists := interpreter.LookupNativeModule(program.Module.Name); exists {
+		for _, c := range native.Constants {
+			exports = append(exports, c.Value.Type()+" "+c.Name)
+		}
+		for _, f := range native.Functions {
+			exports = append(exports, "kazi "+f.Signature())
+		}
+	}
+	for _, node := range program.Functions {
+		var description, name string
+		switch n := node.(type) {
+		case ast.FunctionNode:
+			var params []string
+			for _, param := range n.Parameters {
+				params = append(params, strings.TrimSpace(param.Type+" "+param.Name))
+			}
+			name = n.Name
+			description = fmt.Sprintf("kazi %s(%s)", n.Name, strings.Join(params, ", "))
+		case ast.ClassNode:
+			name = n.Name
+			description = "darasa " + n.Name
+		case ast.VariableDeclarationNode:
+			name = n.Name
+			description = strings.TrimSpace(n.Type + " " + n.Name)
+		default:
+			continue
+		}
+		if len(exported) == 0 || exported[name] {
+			exports = append(exports, description)
+		}
+	}
+	return exports
+}
+
+// SetModulePath sets the directories searched for imports that are not found
+// next to the importing file. It defaults to the KWENDA_PATH environment variable.
+func (r *Runtime) SetModulePath(dirs []string) {
+	r.modulePath = dirs
+}
+
+// SetStdlib replaces the standard library, which is searched after the importing
+// file's directory and the module path. It defaults to the modules built into
+// the executable.
+func (r *Runtime) SetStdlib(fsys fs.FS) {
+	r.stdlib = fsys
+}
+
+// loadImports loads each imported module into env: as a namespace named after
+// the module or its alias, or with kutoka as the listed names themselves
+func (r *Runtime) loadImports(imports []ast.ImportNode, env *interpreter.Environment) error {
+	for _, imp := range imports {
+		module, err := r.loadModule(imp)
+		if err != nil {
+			return err
+		}
+		if len(imp.Items) > 0 {
+			if err := module.ImportInto(env, imp.Items, imp.Pos); err != nil {
+				return err
+			}
+			continue
+		}
+		// The namespace is the alias, else the short name the module was imported
+		// by, else the module's own name
+		name := imp.ImportName
+		if name == "" && isShortName(imp.ModulePath) {
+			name = imp.ModulePath
+		}
+		if name == "" {
+			name = module.Name
+		}
+		env.Modules[name] = module
+	}
+	return nil
+}
+
+// loadModule runs a module file the first time it is imported and returns the
+// module; later imports of the same file share it
+func (r *Runtime) loadModule(imp ast.ImportNode) (*interpreter.ModuleValue, error) {
+	file, source, err := r.resolveModule(imp)
+	if err != nil {
+		return nil, err
+	}
+	key := moduleKey(file)
+	if module, exists := r.modules[key]; exists {
+		return module, nil
+	}
+
+	if len(r.loading) == 0 && imp.Pos.File != "" {
+		// The program itself starts the chain, so importing it back is a cycle too
+		r.loading = append(r.loading, imp.Pos.File)
+		defer func() { r.loading = nil }()
+	}
+
+	// A module still loading further up the chain imports itself through this one
+	for i, loading := range r.loading {
+		if moduleKey(loading) == key {
+			chain := strings.Join(append(append([]string{}, r.loading[i:]...), file), " -> ")
+			return nil, interpreter.ErrorValue{
+				Message: "Moduli zinaletana kwa mzunguko: " + chain,
+				Context: "Import cycle: " + chain,
+				Pos:     imp.Pos,
+			}
+		}
+	}
+	r.loading = append(r.loading, file)
+	defer func() { r.loading = r.loading[:len(r.loading)-1] }()
+
+	program := parser.ParseProgram(lexer.LexFile(file, string(source)))
+	if len(program.Errors) > 0 {
+		return nil, &SyntaxError{Errors: program.Errors}
+	}
+
+	// Modules get their own namespace but share the program's input, output and options
+	moduleEnv := interpreter.NewEnvironment()
+	moduleEnv.Streams = r.env.Streams
+	moduleEnv.Options = r.env.Options
+	exports := program.Module.Exports
+	if native, exists := interpreter.LookupNativeModule(program.Module.Name); exists {
+		// The Go half of the module is there before the file runs, and is always public
+		native.Install(moduleEnv)
+		if len(exports) > 0 {
+			exports = append(native.Names(), exports...)
+		}
+	}
+	if err := r.loadImports(program.Imports, moduleEnv); err != nil {
+		return nil, err
+	}
+	for _, node := range program.Functions {
+		if _, err := r.exec(node, moduleEnv); err != nil {
+			return nil, err
+		}
+	}
+
+	// The namespace is named by moduli, or else after the file (modules/math.swh is math)
+	name := program.Module.Name
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(filepath.ToSlash(file)), ".swh")
+	}
+	module := interpreter.NewModule(name, moduleEnv, exports)
+	r.modules[key] = module
+	return module, nil
+}
+
+// resolveModule finds and reads the file an import refers to. A relative path is
+// looked up next to the importing file (or in the working directory for code
+// that has no file), then in each module path directory, then in the standard
+// library, where modules/ may prefix the file; a short name is looked up in the
+// standard library only. It returns
+// the file's name as shown in error messages.
+func (r *Runtime) resolveModule(imp ast.ImportNode) (string, []byte, error) {
+	if filepath.IsAbs(imp.ModulePath) {
+		source, err := os.ReadFile(imp.ModulePath)
+		if err != nil {
+			return "", nil, unreadableModule(imp, err)
+		}
+		return imp.ModulePath, source, nil
+	}
+
+	// A short name without .swh (leta "hesabu") always means the standard library,
+	// so a program's own files cannot shadow it
+	stdlibPath := imp.ModulePath
+	var searched []string
+	if isShortName(imp.ModulePath) {
+		stdlibPath = imp.ModulePath + ".swh"
+		if file, exists := stdlibNames[imp.ModulePath]; exists {
+			stdlibPath = file
+		}
+	} else {
+		dirs := append([]string{filepath.Dir(imp.Pos.File)}, r.modulePath...)
+		for _, dir := range dirs {
+			file := filepath.Join(dir, imp.ModulePath)
+			source, err := os.ReadFile(file)
+			if err == nil {
+				return file, source, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", nil, unreadableModule(imp, err)
+			}
+			searched = append(searched, dir)
+		}
+	}
+
+	if r.stdlib != nil {
+		// The standard library is the modules directory, which programs used to
+		// import from by path (leta "modules/math.swh"); that form still finds it
+		name := strings.TrimPrefix(path.Clean(filepath.ToSlash(stdlibPath)), "modules/")
+		if source, err := fs.ReadFile(r.stdlib, name); err == nil {
+			return stdlibPrefix + name, source, nil
+		}
+		searched = append(searched, "<stdlib>")
+	}
+
+	return "", nil, interpreter.ErrorValue{
+		Message: fmt.Sprintf("Moduli '%s' haipatikani", imp.ModulePath),
+		Context: fmt.Sprintf("Module '%s' not found; searched %s", imp.ModulePath, strings.Join(searched, ", ")),
+		Pos:     imp.Pos,
+	}
+}
+
+// isShortName reports whether an import names a module without a path or the
+// .swh extension (leta "hesabu")
+func isShortName(modulePath string) bool {
+	return path.Ext(modulePath) == "" && !strings.ContainsAny(modulePath, `/\`)
+}
+
+// unreadableModule is the error for a module file that exists but cannot be read
+func unreadableModule(imp ast.ImportNode, err error) error {
+	return interpreter.ErrorValue{
+		Message: fmt.Sprintf("Haiwezi kusoma moduli '%s'", imp.ModulePath),
+		Context: fmt.Sprintf("Cannot read module '%s': %v", imp.ModulePath, err),
+		Pos:     imp.Pos,
+	}
+}
+
+// moduleKey identifies a module file however it was reached, so that each file
+// is loaded once
+func moduleKey(file string) string {
+	if strings.HasPrefix(file, stdlibPrefix) {
+		return file
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return file
+}
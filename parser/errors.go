@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"fmt"
+	"kwenda/ast"
+	"kwenda/lexer"
+)
+
+// Error is a syntax error found while parsing, with a bilingual message
+type Error struct {
+	Pos     ast.Pos // Where the error was found
+	Message string  // Swahili description
+	English string  // English description
+}
+
+// Error formats the diagnostic as file:line:column: ujumbe (message)
+func (e Error) Error() string {
+	message := e.Message
+	if e.English != "" {
+		message += " (" + e.English + ")"
+	}
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + message
+	}
+	return message
+}
+
+// describeToken names a token for use in an error message
+func describeToken(tok lexer.Token, atEnd bool) (string, string) {
+	if atEnd {
+		return "mwisho wa faili", "end of file"
+	}
+	if tok.Type == lexer.TokenString {
+		quoted := fmt.Sprintf("%q", tok.Value)
+		return "maneno " + quoted, "string " + quoted
+	}
+	return "'" + tok.Value + "'", "'" + tok.Value + "'"
+}
+
+// errorAt records a diagnostic at the given token
+func (p *Parser) errorAt(tok lexer.Token, message string, english string) {
+	pos := posOf(tok)
+	p.errorLine = pos.Line
+	p.failures++
+	// One mistake often trips several rules on the same line; keep only the first
+	for _, err := range p.errors {
+		if err.Pos.File == pos.File && err.Pos.Line == pos.Line {
+			return
+		}
+	}
+	p.errors = append(p.errors, Error{Pos: pos, Message: message, English: english})
+}
+
+// errorHere records a diagnostic at the current token (or at the last token at end of input)
+func (p *Parser) errorHere(message string, english string) {
+	p.errorAt(p.current(), message, english)
+}
+
+// errorMissing records a diagnostic about something missing before the current token.
+// When the current token starts a new line, the statement on the previous line is the
+// incomplete one, so the error is reported there.
+func (p *Parser) errorMissing(message string, english string) {
+	tok := p.current()
+	if p.pos > 0 && (p.atEnd() || tok.Line > p.tokens[p.pos-1].Line) {
+		tok = p.tokens[p.pos-1]
+	}
+	p.errorAt(tok, message, english)
+}
+
+// unexpected reports that the current token cannot appear here
+func (p *Parser) unexpected() {
+	sw, en := describeToken(p.peek(), p.atEnd())
+	p.errorHere("Tokeni isiyotarajiwa "+sw, "unexpected "+en)
+}
+
+// expect consumes the given punctuation or operator, reporting an error if it is missing
+func (p *Parser) expect(value string) bool {
+	tok := p.peek()
+	if !p.atEnd() && tok.Type != lexer.TokenString && tok.Value == value {
+		p.pos++
+		return true
+	}
+	sw, en := describeToken(tok, p.atEnd())
+	p.errorMissing(
+		fmt.Sprintf("Nilitarajia '%s' lakini nimepata %s", value, sw),
+		fmt.Sprintf("expected '%s' but found %s", value, en),
+	)
+	return false
+}
+
+// expectName consumes an identifier-like token (identifiers and non-reserved keywords)
+func (p *Parser) expectName(what string, whatEnglish string) (lexer.Token, bool) {
+	tok := p.peek()
+	if !p.atEnd() && (tok.Type == lexer.TokenIdentifier || (tok.Type == lexer.TokenKeyword && !reservedWords[tok.Value])) {
+		p.pos++
+		return tok, true
+	}
+	sw, en := describeToken(tok, p.atEnd())
+	p.errorHere(
+		fmt.Sprintf("Nilitarajia %s lakini nimepata %s", what, sw),
+		fmt.Sprintf("expected %s but found %s", whatEnglish, en),
+	)
+	return tok, false
+}
+
+// synchronize skips tokens after an error until the start of the next statement:
+// the first token on a later line, a ';', or the '}' closing the current block.
+// Nested braces are skipped as a unit so a broken header does not leak its body.
+func (p *Parser) synchronize(start int) {
+	line := p.errorLine
+	if p.pos == start && !p.atEnd() && !p.isPunctuation("}") {
+		p.pos++ // Always make progress
+	}
+
+	depth := 0
+	for !p.atEnd() {
+		tok := p.peek()
+		if tok.Type == lexer.TokenPunctuation {
+			switch tok.Value {
+			case "{":
+				depth++
+			case "}":
+				if depth == 0 {
+					return
+				}
+				depth--
+				p.pos++
+				continue
+			case ";":
+				if depth == 0 {
+					p.pos++
+					return
+				}
+			}
+		}
+		if depth == 0 && tok.Line > line {
+			return
+		}
+		p.pos++
+	}
+}
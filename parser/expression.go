@@ -0,0 +1,397 @@
+package parser
+
+import (
+	"kwenda/ast"
+	"kwenda/lexer"
+)
+
+// Binding power of each binary operator. Higher values bind tighter, so
+// 2 + 3 * 4 groups as 2 + (3 * 4). All binary operators are left-associative
+// except **, which groups from the right (2 ** 3 ** 2 is 2 ** 9).
+var binaryPrecedence = map[string]int{
+	"au": 1, // logical OR
+	"na": 2, // logical AND
+	"==": 3,
+	"!=": 3,
+	"<":  4,
+	"<=": 4,
+	">":  4,
+	">=": 4,
+	"+":  5,
+	"-":  5,
+	"*":  6,
+	"/":  6,
+	"%":  6,
+	"**": 8, // Binds tighter than prefix operators, so -2 ** 2 is -(2 ** 2)
+}
+
+// Keywords that are part of the language syntax. Other keywords name built-in
+// functions and may also be used as ordinary identifiers (e.g., a parameter named mwisho).
+var reservedWords = map[string]bool{
+	"kazi": true, "kama": true, "sivyo": true, "kwa": true, "wakati": true,
+	"rudisha": true, "namba": true, "kweli": true, "uwongo": true, "na": true,
+	"au": true, "si": true, "vunja": true, "endelea": true, "boolean": true,
+	"maneno": true, "orodha": true, "leta": true, "kutoka": true, "moduli": true,
+	"umma": true, "jaribu": true, "shika": true, "hatimaye": true, "tupa": true,
+	"darasa": true, "unda": true, "hii": true, "kamusi": true, "lambda": true,
+	"chagua": true,
+}
+
+// Prefix operators bind tighter than any binary operator (-2 * 3 is (-2) * 3)
+const unaryPrecedence = 7
+
+// ParseExpression parses an expression, honouring operator precedence and parentheses
+func ParseExpression(tokens []lexer.Token) ast.ASTNode {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return NewParser(tokens).parseExpression(0)
+}
+
+// ParseDictionaryLiteral parses dictionary literals like {key: value, key2: value2}
+func ParseDictionaryLiteral(tokens []lexer.Token) ast.ASTNode {
+	if len(tokens) < 2 || tokens[0].Value != "{" {
+		return nil
+	}
+	return NewParser(tokens).parseDictionaryLiteral()
+}
+
+// binaryOperator returns the precedence of the current token if it is a binary operator
+func (p *Parser) binaryOperator() (string, int, bool) {
+	tok := p.peek()
+	if tok.Type != lexer.TokenOperator && tok.Type != lexer.TokenKeyword {
+		return "", 0, false
+	}
+	prec, ok := binaryPrecedence[tok.Value]
+	return tok.Value, prec, ok
+}
+
+// parseExpression parses an expression whose operators all bind at least as tightly as minPrec
+func (p *Parser) parseExpression(minPrec int) ast.ASTNode {
+	left := p.parseUnary()
+	if left == nil {
+		return nil
+	}
+
+	for {
+		op, prec, ok := p.binaryOperator()
+		if !ok || prec < minPrec {
+			break
+		}
+		p.pos++
+
+		// Left-associative: the right operand may only contain tighter operators
+		rightPrec := prec + 1
+		if op == "**" {
+			rightPrec = prec
+		}
+		right := p.parseExpression(rightPrec)
+		if right == nil {
+			break
+		}
+		left = ast.BinaryOpNode{
+			Left:  left,
+			Op:    op,
+			Right: right,
+			Pos:   ast.PosOf(left),
+		}
+	}
+
+	return left
+}
+
+// parseUnary parses prefix operators (- and si) followed by their operand
+func (p *Parser) parseUnary() ast.ASTNode {
+	tok := p.peek()
+	if (tok.Type == lexer.TokenOperator && tok.Value == "-") || (tok.Type == lexer.TokenKeyword && tok.Value == "si") {
+		p.pos++
+		operand := p.parseExpression(unaryPrecedence)
+		if operand == nil {
+			return nil
+		}
+		return ast.UnaryOpNode{
+			Op:      tok.Value,
+			Operand: operand,
+			Pos:     posOf(tok),
+		}
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses literals, identifiers, calls, grouping parentheses and other atoms
+func (p *Parser) parsePrimary() ast.ASTNode {
+	if p.atEnd() {
+		p.errorMissing("Usemi unakosekana kabla ya mwisho wa faili", "missing expression before end of file")
+		return nil
+	}
+	tok := p.peek()
+
+	switch tok.Type {
+	case lexer.TokenNumber:
+		p.pos++
+		return ast.NumberNode{Value: tok.Value, Pos: posOf(tok)}
+
+	case lexer.TokenString:
+		p.pos++
+		return ast.StringNode{Value: tok.Value, Pos: posOf(tok)}
+
+	case lexer.TokenStringPart:
+		return p.parseInterpolatedString()
+
+	case lexer.TokenPunctuation:
+		switch tok.Value {
+		case "(":
+			// Grouping parentheses
+			p.pos++
+			inner := p.parseExpression(0)
+			if inner == nil || !p.expect(")") {
+				return nil
+			}
+			return p.parsePostfix(inner)
+		case "[":
+			return p.parseArrayLiteral()
+		case "{":
+			if !p.inCondition {
+				return p.parseDictionaryLiteral()
+			}
+		}
+
+	case lexer.TokenKeyword, lexer.TokenBoolean:
+		switch tok.Value {
+		case "kweli":
+			p.pos++
+			return ast.BooleanNode{Value: true, Pos: posOf(tok)}
+		case "uwongo":
+			p.pos++
+			return ast.BooleanNode{Value: false, Pos: posOf(tok)}
+		case "hii":
+			p.pos++
+			return p.parsePostfix(ast.ThisNode{Pos: posOf(tok)})
+		case "lambda":
+			return p.parseLambda()
+		case "unda":
+			return p.parseNewInstance()
+		}
+		// Keywords that can be called like functions (ingiza)
+		if p.peekAt(1).Value == "(" {
+			return p.parseCall()
+		}
+		if !reservedWords[tok.Value] {
+			p.pos++
+			return p.parsePostfix(ast.IdentifierNode{Value: tok.Value, Pos: posOf(tok)})
+		}
+
+	case lexer.TokenIdentifier:
+		if p.peekAt(1).Value == "(" {
+			return p.parseCall()
+		}
+		p.pos++
+		return p.parsePostfix(ast.IdentifierNode{Value: tok.Value, Pos: posOf(tok)})
+	}
+
+	sw, en := describeToken(tok, false)
+	p.errorMissing("Nilitarajia usemi lakini nimepata "+sw, "expected an expression but found "+en)
+	return nil
+}
+
+// parseInterpolatedString lowers "Habari ${jina}!" into "Habari " + jina + "!".
+// The lexer emits a string part before each interpolated expression and a plain
+// string token for the text after the last one.
+func (p *Parser) parseInterpolatedString() ast.ASTNode {
+	tok := p.peek()
+	// Starting from a string makes + concatenate whatever the expressions are
+	var result ast.ASTNode = ast.StringNode{Value: tok.Value, Pos: posOf(tok)}
+
+	for p.peek().Type == lexer.TokenStringPart {
+		part := p.peek()
+		p.pos++
+		if part.Value != "" && part != tok {
+			result = ast.BinaryOpNode{Left: result, Op: "+", Right: ast.StringNode{Value: part.Value, Pos: posOf(part)}, Pos: posOf(tok)}
+		}
+		if p.peek().Type == lexer.TokenStringPart || p.peek().Type == lexer.TokenString {
+			p.errorHere("Usemi unakosekana ndani ya ${ }", "missing expression inside ${ }")
+			return nil
+		}
+		value := p.parseExpression(0)
+		if value == nil {
+			return nil
+		}
+		result = ast.BinaryOpNode{Left: result, Op: "+", Right: value, Pos: posOf(tok)}
+	}
+
+	// The text after the last interpolation
+	rest := p.peek()
+	if rest.Type != lexer.TokenString {
+		sw, en := describeToken(rest, p.atEnd())
+		p.errorMissing("Nilitarajia '}' kufunga ${ lakini nimepata "+sw, "expected '}' closing ${ but found "+en)
+		return nil
+	}
+	p.pos++
+	if rest.Value != "" {
+		result = ast.BinaryOpNode{Left: result, Op: "+", Right: ast.StringNode{Value: rest.Value, Pos: posOf(rest)}, Pos: posOf(tok)}
+	}
+	return result
+}
+
+// parsePostfix parses any chain of member accesses, method calls, indexes and
+// calls after an object (e.g., mtu.anwani.mji, matrix[i][j] or tengeneza()(5))
+func (p *Parser) parsePostfix(object ast.ASTNode) ast.ASTNode {
+	for {
+		switch {
+		case p.isPunctuation("."):
+			// Method call or member access (e.g., mtu.salamu() or hii.jina)
+			p.pos++
+			memberTok := p.peek()
+			if p.atEnd() || (memberTok.Type != lexer.TokenIdentifier && memberTok.Type != lexer.TokenKeyword) {
+				sw, en := describeToken(memberTok, p.atEnd())
+				p.errorHere("Nilitarajia jina baada ya '.' lakini nimepata "+sw, "expected a name after '.' but found "+en)
+				return nil
+			}
+			member := memberTok.Value
+			p.pos++
+			if p.isPunctuation("(") {
+				p.pos++
+				args, ok := p.parseList(")")
+				if !ok {
+					return nil
+				}
+				object = ast.MethodCallNode{
+					Object: object,
+					Method: member,
+					Args:   args,
+					Pos:    ast.PosOf(object),
+				}
+				continue
+			}
+			object = ast.MemberAccessNode{
+				Object: object,
+				Member: member,
+				Pos:    ast.PosOf(object),
+			}
+
+		case p.isPunctuation("["):
+			// Array/dictionary access (e.g., arr[0] or dict["key"])
+			p.pos++
+			index := p.parseExpression(0)
+			if index == nil || !p.expect("]") {
+				return nil
+			}
+			object = ast.ArrayAccessNode{
+				Array: object,
+				Index: index,
+				Pos:   ast.PosOf(object),
+			}
+
+		case p.isPunctuation("("):
+			// Calling the function an expression gives (e.g., hifadhi["f"](2))
+			p.pos++
+			args, ok := p.parseList(")")
+			if !ok {
+				return nil
+			}
+			object = ast.CallNode{Callee: object, Args: args, Pos: ast.PosOf(object)}
+
+		default:
+			return object
+		}
+	}
+}
+
+// parseCall parses a function call such as andika(x, y) or ingiza("prompt")
+func (p *Parser) parseCall() ast.ASTNode {
+	tok := p.peek()
+	name := tok.Value
+	p.pos += 2 // Skip name and "("
+	args, ok := p.parseList(")")
+	if !ok {
+		return nil
+	}
+
+	return p.parsePostfix(ast.FunctionCallNode{
+		Name: name,
+		Args: args,
+		Pos:  posOf(tok),
+	})
+}
+
+// parseList parses comma-separated expressions up to and including the closing token
+func (p *Parser) parseList(closing string) ([]ast.ASTNode, bool) {
+	var items []ast.ASTNode
+	for !p.isPunctuation(closing) {
+		item := p.parseExpression(0)
+		if item == nil {
+			return items, false
+		}
+		items = append(items, item)
+		if p.isPunctuation(",") {
+			p.pos++
+		} else if !p.isPunctuation(closing) {
+			p.expect(closing)
+			return items, false
+		}
+	}
+	p.pos++ // Skip the closing token
+	return items, true
+}
+
+// parseArrayLiteral parses an array literal such as [1, 2, 3]
+func (p *Parser) parseArrayLiteral() ast.ASTNode {
+	tok := p.peek()
+	p.pos++ // Skip "["
+	elements, ok := p.parseList("]")
+	if !ok {
+		return nil
+	}
+	return ast.ArrayNode{Elements: elements, Pos: posOf(tok)}
+}
+
+// parseDictionaryLiteral parses a dictionary literal such as {"jina": "Amina", "umri": 25}
+func (p *Parser) parseDictionaryLiteral() ast.ASTNode {
+	tok := p.peek()
+	p.pos++ // Skip "{"
+	pairs := []ast.DictionaryPair{}
+	for !p.isPunctuation("}") {
+		key := p.parseExpression(0)
+		if key == nil || !p.expect(":") {
+			return nil
+		}
+		value := p.parseExpression(0)
+		if value == nil {
+			return nil
+		}
+		pairs = append(pairs, ast.DictionaryPair{Key: key, Value: value})
+		if p.isPunctuation(",") {
+			p.pos++
+		} else if !p.isPunctuation("}") {
+			p.expect("}")
+			return nil
+		}
+	}
+	p.pos++ // Skip "}"
+	return ast.DictionaryNode{Pairs: pairs, Pos: posOf(tok)}
+}
+
+// parseNewInstance parses class instantiation (unda ClassName(args))
+func (p *Parser) parseNewInstance() ast.ASTNode {
+	tok := p.peek()
+	p.pos++ // Skip "unda"
+	classTok, ok := p.expectName("jina la darasa", "a class name")
+	if !ok {
+		return nil
+	}
+	className := classTok.Value
+
+	var args []ast.ASTNode
+	if p.isPunctuation("(") {
+		p.pos++
+		if args, ok = p.parseList(")"); !ok {
+			return nil
+		}
+	}
+
+	return p.parsePostfix(ast.NewInstanceNode{
+		ClassName: className,
+		Args:      args,
+		Pos:       posOf(tok),
+	})
+}
@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"kwenda/ast"
+	"kwenda/lexer"
+)
+
+// parseMatchStatement parses chagua statements:
+//
+//	chagua thamani {
+//	    wakati 1, 2 { ... }
+//	    wakati 10 hadi 20 { ... }
+//	    wakati [x, y] { ... }
+//	    wakati Mtu(jina) { ... }
+//	    sivyo { ... }
+//	}
+func (p *Parser) parseMatchStatement() ast.ASTNode {
+	chaguaTok := p.peek()
+	p.pos++ // Skip "chagua"
+
+	subject := p.parseCondition()
+	if subject == nil {
+		return nil
+	}
+	if !p.expect("{") {
+		return nil
+	}
+
+	match := ast.MatchNode{Subject: subject, Pos: posOf(chaguaTok)}
+	for !p.isPunctuation("}") {
+		if p.atEnd() {
+			p.expect("}")
+			return nil
+		}
+		tok := p.peek()
+		switch {
+		case p.isKeyword("wakati"):
+			if match.HasDefault {
+				p.errorHere("Tawi la 'wakati' haliwezi kuja baada ya 'sivyo'", "a 'wakati' arm cannot come after 'sivyo'")
+				return nil
+			}
+			arm, ok := p.parseMatchCase()
+			if !ok {
+				return nil
+			}
+			match.Cases = append(match.Cases, arm)
+		case p.isKeyword("sivyo"):
+			if match.HasDefault {
+				p.errorHere("chagua inaweza kuwa na 'sivyo' moja tu", "chagua can only have one 'sivyo' arm")
+				return nil
+			}
+			p.pos++ // Skip "sivyo"
+			match.Default = p.parseBody()
+			match.HasDefault = true
+		default:
+			sw, en := describeToken(tok, false)
+			p.errorHere("Nilitarajia 'wakati' au 'sivyo' ndani ya chagua lakini nimepata "+sw,
+				"expected 'wakati' or 'sivyo' inside chagua but found "+en)
+			return nil
+		}
+	}
+	p.pos++ // Skip "}"
+	return match
+}
+
+// parseMatchCase parses one arm: wakati followed by comma-separated patterns and a body
+func (p *Parser) parseMatchCase() (ast.MatchCase, bool) {
+	wakatiTok := p.peek()
+	p.pos++ // Skip "wakati"
+	arm := ast.MatchCase{Pos: posOf(wakatiTok)}
+	for {
+		pattern := p.parsePattern()
+		if pattern == nil {
+			return arm, false
+		}
+		arm.Patterns = append(arm.Patterns, pattern)
+		if !p.isPunctuation(",") {
+			break
+		}
+		p.pos++
+	}
+	arm.Body = p.parseBody()
+	return arm, true
+}
+
+// parsePattern parses a pattern: a literal, a range (1 hadi 5), a variable (or
+// _), an array shape, a dictionary shape or a class with its properties
+func (p *Parser) parsePattern() ast.ASTNode {
+	tok := p.peek()
+	switch {
+	case tok.Type == lexer.TokenNumber || (p.isOperator("-") && p.peekAt(1).Type == lexer.TokenNumber):
+		low := p.parseNumberPattern()
+		if next := p.peek(); next.Type != lexer.TokenIdentifier || next.Value != "hadi" {
+			return ast.LiteralPattern{Value: low, Pos: posOf(tok)}
+		}
+		p.pos++ // Skip "hadi"
+		if !p.isOperator("-") && p.peek().Type != lexer.TokenNumber {
+			sw, en := describeToken(p.peek(), p.atEnd())
+			p.errorMissing("Nilitarajia namba baada ya 'hadi' lakini nimepata "+sw, "expected a number after 'hadi' but found "+en)
+			return nil
+		}
+		return ast.RangePattern{Low: low, High: p.parseNumberPattern(), Pos: posOf(tok)}
+
+	case tok.Type == lexer.TokenString:
+		p.pos++
+		return ast.LiteralPattern{Value: ast.StringNode{Value: tok.Value, Pos: posOf(tok)}, Pos: posOf(tok)}
+
+	case tok.Value == "kweli" || tok.Value == "uwongo":
+		p.pos++
+		return ast.LiteralPattern{Value: ast.BooleanNode{Value: tok.Value == "kweli", Pos: posOf(tok)}, Pos: posOf(tok)}
+
+	case p.isPunctuation("["):
+		return p.parseArrayPattern()
+
+	case p.isPunctuation("{"):
+		return p.parseDictPattern()
+
+	case tok.Type == lexer.TokenIdentifier && p.peekAt(1).Value == "(":
+		return p.parseInstancePattern()
+
+	case tok.Type == lexer.TokenIdentifier:
+		p.pos++
+		return ast.BindingPattern{Name: tok.Value, Pos: posOf(tok)}
+	}
+
+	sw, en := describeToken(tok, p.atEnd())
+	p.errorMissing("Nilitarajia muundo wa kulinganisha lakini nimepata "+sw, "expected a pattern but found "+en)
+	return nil
+}
+
+// parseNumberPattern parses a number literal in a pattern, with an optional minus sign
+func (p *Parser) parseNumberPattern() ast.NumberNode {
+	tok := p.peek()
+	sign := ""
+	if p.isOperator("-") {
+		sign = "-"
+		p.pos++
+	}
+	number := p.peek()
+	p.pos++
+	return ast.NumberNode{Value: sign + number.Value, Pos: posOf(tok)}
+}
+
+// parseArrayPattern parses an array shape such as [x, 0, _]
+func (p *Parser) parseArrayPattern() ast.ASTNode {
+	tok := p.peek()
+	p.pos++ // Skip "["
+	pattern := ast.ArrayPattern{Pos: posOf(tok)}
+	for !p.isPunctuation("]") {
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+		if p.isPunctuation(",") {
+			p.pos++
+		} else if !p.expect("]") {
+			return nil
+		} else {
+			return pattern
+		}
+	}
+	p.pos++ // Skip "]"
+	return pattern
+}
+
+// parseDictPattern parses a dictionary shape such as {"jina": j, "umri": 30}
+func (p *Parser) parseDictPattern() ast.ASTNode {
+	tok := p.peek()
+	p.pos++ // Skip "{"
+	pattern := ast.DictPattern{Pos: posOf(tok)}
+	for !p.isPunctuation("}") {
+		key := p.peek()
+		if key.Type != lexer.TokenString {
+			sw, en := describeToken(key, p.atEnd())
+			p.errorMissing("Nilitarajia ufunguo wa maneno lakini nimepata "+sw, "expected a string key but found "+en)
+			return nil
+		}
+		p.pos++
+		if !p.expect(":") {
+			return nil
+		}
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+		pattern.Keys = append(pattern.Keys, key.Value)
+		pattern.Values = append(pattern.Values, value)
+		if p.isPunctuation(",") {
+			p.pos++
+		} else if !p.expect("}") {
+			return nil
+		} else {
+			return pattern
+		}
+	}
+	p.pos++ // Skip "}"
+	return pattern
+}
+
+// parseInstancePattern parses a class pattern such as Mtu(jina, umri: 30)
+func (p *Parser) parseInstancePattern() ast.ASTNode {
+	tok := p.peek()
+	p.pos += 2 // Skip the class name and "("
+	pattern := ast.InstancePattern{ClassName: tok.Value, Pos: posOf(tok)}
+	for !p.isPunctuation(")") {
+		property, ok := p.expectName("jina la sifa", "a property name")
+		if !ok {
+			return nil
+		}
+		var value ast.ASTNode = ast.BindingPattern{Name: property.Value, Pos: posOf(property)}
+		if p.isPunctuation(":") {
+			p.pos++
+			if value = p.parsePattern(); value == nil {
+				return nil
+			}
+		}
+		pattern.Properties = append(pattern.Properties, property.Value)
+		pattern.Patterns = append(pattern.Patterns, value)
+		if p.isPunctuation(",") {
+			p.pos++
+		} else if !p.expect(")") {
+			return nil
+		} else {
+			return pattern
+		}
+	}
+	p.pos++ // Skip ")"
+	return pattern
+}
@@ -3,1096 +3,689 @@ package parser
 import (
 	"kwenda/ast"
 	"kwenda/lexer"
+	"sort"
 )
 
 // ProgramNode represents the entire program
 type ProgramNode struct {
 	Functions []ast.ASTNode
 	Imports   []ast.ImportNode
+	Module    ast.ModuleNode // The file seen as a module: its moduli name and umma exports
+	Errors    []Error        // Syntax errors; the program must not run if any are present
 }
 
-// ParseProgram parses the entire program with multiple functions and imports
-func ParseProgram(tokens []lexer.Token) ProgramNode {
-	var functions []ast.ASTNode
-	var imports []ast.ImportNode
-	i := 0
+// Parser turns a token stream into AST nodes, collecting syntax errors as it goes
+type Parser struct {
+	tokens      []lexer.Token
+	pos         int
+	errors      []Error
+	errorLine   int  // Line of the most recent error, where recovery starts from
+	failures    int  // Errors reported so far, including duplicates that were not kept
+	inCondition bool // A '{' ends the expression instead of starting a dictionary
+}
 
-	for i < len(tokens) {
-		// Skip empty tokens
-		if tokens[i].Value == "" {
-			i++
+// NewParser creates a parser over the given tokens. Error tokens from the lexer
+// become diagnostics and are removed from the stream.
+func NewParser(tokens []lexer.Token) *Parser {
+	p := &Parser{}
+	for _, tok := range tokens {
+		if tok.Type == lexer.TokenError {
+			p.errors = append(p.errors, Error{Pos: posOf(tok), Message: tok.Value})
 			continue
 		}
+		p.tokens = append(p.tokens, tok)
+	}
+	return p
+}
 
-		// Handle import statements (leta "module.swh")
-		if tokens[i].Value == "leta" && i+1 < len(tokens) && tokens[i+1].Type == lexer.TokenString {
-			imports = append(imports, ast.ImportNode{
-				ModulePath: tokens[i+1].Value,
-			})
-			i += 2
-			continue
-		}
+// Errors returns the syntax errors found so far
+func (p *Parser) Errors() []Error {
+	return p.errors
+}
 
-		// Handle top-level variable declarations
-		if (tokens[i].Value == "namba" || tokens[i].Value == "maneno") && i+3 < len(tokens) && tokens[i+2].Value == "=" {
-			end := i + 3
-			for end < len(tokens) && tokens[end].Value != "namba" && tokens[end].Value != "maneno" && tokens[end].Value != "kazi" {
-				end++
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				functions = append(functions, stmt)
-			}
-			i = end
-			continue
-		}
+// posOf converts a token's location into an AST position
+func posOf(tok lexer.Token) ast.Pos {
+	return ast.Pos{File: tok.File, Line: tok.Line, Column: tok.Column}
+}
 
-		// Handle class definitions
-		if tokens[i].Value == "darasa" {
-			// Find the end of this class
-			end := i + 1
-			braceCount := 0
-			foundFirstBrace := false
-
-			for end < len(tokens) {
-				if tokens[end].Value == "{" {
-					braceCount++
-					foundFirstBrace = true
-				} else if tokens[end].Value == "}" {
-					braceCount--
-					if braceCount == 0 && foundFirstBrace {
-						end++
-						break
-					}
-				}
-				end++
-			}
+// atEnd reports whether all tokens have been consumed
+func (p *Parser) atEnd() bool {
+	return p.pos >= len(p.tokens)
+}
 
-			// Parse this class
-			class := Parse(tokens[i:end])
-			if class != nil {
-				functions = append(functions, class)
-			}
-			i = end
-			continue
-		}
+// peek returns the current token, or an empty token at the end of input
+func (p *Parser) peek() lexer.Token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return lexer.Token{}
+}
 
-		// Find the end of the current function
-		if tokens[i].Value == "kazi" {
-			// Find the end of this function
-			end := i + 1
-			braceCount := 0
-			foundFirstBrace := false
-
-			for end < len(tokens) {
-				if tokens[end].Value == "{" {
-					braceCount++
-					foundFirstBrace = true
-				} else if tokens[end].Value == "}" {
-					braceCount--
-					if braceCount == 0 && foundFirstBrace {
-						end++
-						break
-					}
-				}
-				end++
-			}
+// peekAt returns the token offset positions ahead of the current one
+func (p *Parser) peekAt(offset int) lexer.Token {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return lexer.Token{}
+}
 
-			// Parse this function
-			function := Parse(tokens[i:end])
-			if function != nil {
-				functions = append(functions, function)
-			}
-			i = end
-		} else {
-			i++
-		}
+// current returns the current token for error reporting; at the end of input it
+// returns the last token so the error still has a position
+func (p *Parser) current() lexer.Token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
 	}
+	if len(p.tokens) > 0 {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return lexer.Token{}
+}
 
-	return ProgramNode{Functions: functions, Imports: imports}
+// isPunctuation reports whether the current token is the given punctuation
+func (p *Parser) isPunctuation(value string) bool {
+	tok := p.peek()
+	return tok.Type == lexer.TokenPunctuation && tok.Value == value
 }
 
-// Parse converts tokens into an Abstract Syntax Tree (AST)
-func Parse(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) == 0 {
-		return nil
-	}
+// isKeyword reports whether the current token is the given keyword
+func (p *Parser) isKeyword(value string) bool {
+	tok := p.peek()
+	return tok.Type == lexer.TokenKeyword && tok.Value == value
+}
 
-	// Handle conditional statements
-	if tokens[0].Value == "kama" {
-		return ParseIfStatement(tokens)
-	}
+// isOperator reports whether the current token is the given operator
+func (p *Parser) isOperator(value string) bool {
+	tok := p.peek()
+	return tok.Type == lexer.TokenOperator && tok.Value == value
+}
 
-	// Handle while loops
-	if tokens[0].Value == "wakati" {
-		return ParseWhileStatement(tokens)
-	}
+// ParseProgram parses the entire program with multiple functions and imports
+func ParseProgram(tokens []lexer.Token) ProgramNode {
+	return parseProgram(tokens, false)
+}
 
-	// Handle for loops
-	if tokens[0].Value == "kwa" {
-		return ParseForStatement(tokens)
-	}
+// ParseInteractive parses input typed at the REPL. Unlike a file, any statement
+// is allowed at the top level, including a bare expression such as 2 + 3 whose
+// value the REPL prints.
+func ParseInteractive(tokens []lexer.Token) ProgramNode {
+	return parseProgram(tokens, true)
+}
 
-	// Handle break statements
-	if tokens[0].Value == "vunja" {
-		return ast.BreakNode{}
-	}
+// parseProgram parses a file, or REPL input when interactive is set
+func parseProgram(tokens []lexer.Token, interactive bool) ProgramNode {
+	p := NewParser(tokens)
+	var functions []ast.ASTNode
+	var imports []ast.ImportNode
+	var module ast.ModuleNode
 
-	// Handle continue statements
-	if tokens[0].Value == "endelea" {
-		return ast.ContinueNode{}
-	}
+	for !p.atEnd() {
+		start := p.pos
+		failures := p.failures
+		tok := p.peek()
 
-	// Handle return statements
-	if tokens[0].Value == "rudisha" {
-		if len(tokens) > 1 {
-			return ast.ReturnNode{
-				Value: ParseExpression(tokens[1:]),
+		switch {
+		case tok.Type == lexer.TokenKeyword && tok.Value == "leta":
+			// Handle import statements (leta "module.swh")
+			if imp, ok := p.parseImport(); ok {
+				imports = append(imports, imp)
 			}
-		}
-		return ast.ReturnNode{Value: nil}
-	}
-
-	// Handle try-catch statements
-	if tokens[0].Value == "jaribu" {
-		return ParseTryStatement(tokens)
-	}
 
-	// Handle throw statements
-	if tokens[0].Value == "tupa" && len(tokens) > 1 {
-		var endIndex = len(tokens)
-		for i := 1; i < len(tokens); i++ {
-			if tokens[i].Value == "}" || tokens[i].Value == ";" {
-				endIndex = i
-				break
+		case tok.Type == lexer.TokenKeyword && tok.Value == "kutoka":
+			// Handle selective imports (kutoka "module.swh" leta a, b)
+			if imp, ok := p.parseFromImport(); ok {
+				imports = append(imports, imp)
 			}
-		}
-		return ast.ThrowNode{Message: ParseExpression(tokens[1:endIndex])}
-	}
 
-	// Handle class definitions
-	if tokens[0].Value == "darasa" {
-		return ParseClassDefinition(tokens)
-	}
+		case tok.Type == lexer.TokenKeyword && tok.Value == "moduli":
+			// Handle the module name (moduli hesabu)
+			p.pos++
+			if name, ok := p.expectName("jina la moduli", "a module name"); ok {
+				module.Name = name.Value
+				module.Pos = posOf(tok)
+			}
 
-	// Handle class instantiation (unda ClassName(args))
-	if tokens[0].Value == "unda" && len(tokens) >= 3 {
-		return ParseNewInstance(tokens)
-	}
+		case tok.Type == lexer.TokenKeyword && tok.Value == "umma":
+			// Handle exported declarations (umma kazi ongeza(...) { ... })
+			p.pos++
+			if !p.startsTopLevelDeclaration() {
+				sw, en := describeToken(p.peek(), p.atEnd())
+				p.errorHere("Nilitarajia kazi, darasa au kigezo baada ya 'umma' lakini nimepata "+sw,
+					"expected a function, class or variable declaration after 'umma' but found "+en)
+				break
+			}
+			if stmt := p.parseStatement(); stmt != nil {
+				functions = append(functions, stmt)
+				module.Exports = append(module.Exports, declaredName(stmt))
+			}
 
-	// Handle method calls as statements (e.g., object.method(args))
-	// Must check before regular function calls
-	if len(tokens) >= 4 && tokens[0].Type == lexer.TokenIdentifier && tokens[1].Value == "." && tokens[3].Value == "(" {
-		var object ast.ASTNode
-		if tokens[0].Value == "hii" {
-			object = ast.ThisNode{}
-		} else {
-			object = ast.IdentifierNode{Value: tokens[0].Value}
-		}
-		return ast.MethodCallNode{
-			Object: object,
-			Method: tokens[2].Value,
-			Args:   ParseArguments(tokens[4:]),
-		}
-	}
+		case p.startsTopLevelDeclaration():
+			// Functions, classes and global variables
+			if stmt := p.parseStatement(); stmt != nil {
+				functions = append(functions, stmt)
+			}
 
-	// Handle function definitions
-	if tokens[0].Value == "kazi" && len(tokens) >= 4 && tokens[2].Value == "(" {
-		return ParseFunctionDefinition(tokens)
-	}
+		case tok.Type == lexer.TokenPunctuation && tok.Value == "}":
+			// A stray closing brace has no block to end here
+			p.errorHere("Mabano '}' hayana '{' inayolingana", "unmatched '}'")
+			p.pos++
+			continue
 
-	// Handle function variable declarations (kazi name = lambda() { ... })
-	if tokens[0].Value == "kazi" && len(tokens) >= 4 && tokens[2].Value == "=" {
-		return ast.VariableDeclarationNode{
-			Name:  tokens[1].Value,
-			Value: ParseExpression(tokens[3:]),
-		}
-	}
+		case interactive:
+			if stmt := p.parseStatementAllowing(true); stmt != nil {
+				functions = append(functions, stmt)
+			}
 
-	// Handle variable declarations
-	if tokens[0].Value == "namba" && len(tokens) >= 4 && tokens[2].Value == "=" {
-		return ast.VariableDeclarationNode{
-			Name:  tokens[1].Value,
-			Value: ParseExpression(tokens[3:]),
+		default:
+			sw, en := describeToken(tok, false)
+			p.errorHere(
+				"Kauli "+sw+" hairuhusiwi nje ya kazi; weka msimbo ndani ya kazi kuu()",
+				"statement starting with "+en+" is not allowed outside a function; put code inside kazi kuu()",
+			)
 		}
-	}
 
-	// Handle boolean variable declarations
-	if tokens[0].Value == "boolean" && len(tokens) >= 4 && tokens[2].Value == "=" {
-		return ast.VariableDeclarationNode{
-			Name:  tokens[1].Value,
-			Value: ParseExpression(tokens[3:]),
+		if p.failures > failures {
+			p.synchronize(start)
 		}
 	}
 
-	// Handle string variable declarations
-	if tokens[0].Value == "maneno" && len(tokens) >= 4 && tokens[2].Value == "=" {
-		return ast.StringVariableDeclarationNode{
-			Name:  tokens[1].Value,
-			Value: ParseExpression(tokens[3:]),
-		}
-	}
+	// Lexer errors were collected up front; report everything in source order
+	sort.SliceStable(p.errors, func(i, j int) bool {
+		a, b := p.errors[i].Pos, p.errors[j].Pos
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
 
-	// Handle dictionary declarations (kamusi name = {...} or kamusi name = func())
-	if tokens[0].Value == "kamusi" && len(tokens) >= 4 && tokens[2].Value == "=" {
-		var value ast.ASTNode
-		if tokens[3].Value == "{" {
-			value = ParseDictionaryLiteral(tokens[3:])
-		} else {
-			value = ParseExpression(tokens[3:])
-		}
-		return ast.DictionaryDeclarationNode{
-			Name:  tokens[1].Value,
-			Value: value,
-		}
-	}
+	module.Functions = functions
+	return ProgramNode{Functions: functions, Imports: imports, Module: module, Errors: p.errors}
+}
 
-	// Handle array declarations
-	if tokens[0].Value == "orodha" && len(tokens) >= 5 && tokens[3].Value == "=" {
-		arrayLiteral := ParseArrayLiteral(tokens[4:])
-		var elements []ast.ASTNode
-		if arrayNode, ok := arrayLiteral.(ast.ArrayNode); ok {
-			elements = arrayNode.Elements
-		}
-		return ast.ArrayDeclarationNode{
-			Name:     tokens[2].Value,
-			Type:     tokens[1].Value,
-			Elements: elements,
-		}
-	}
+// declaredName returns the name a top-level declaration defines
+func declaredName(node ast.ASTNode) string {
+	switch n := node.(type) {
+	case ast.FunctionNode:
+		return n.Name
+	case ast.ClassNode:
+		return n.Name
+	case ast.VariableDeclarationNode:
+		return n.Name
+	case ast.StringVariableDeclarationNode:
+		return n.Name
+	case ast.ArrayDeclarationNode:
+		return n.Name
+	case ast.DictionaryDeclarationNode:
+		return n.Name
+	case ast.ClassVariableDeclarationNode:
+		return n.VarName
+	}
+	return ""
+}
 
-	// Handle member assignment (e.g., hii.jina = "Amina")
-	if len(tokens) >= 5 && tokens[1].Value == "." && tokens[3].Value == "=" {
-		var object ast.ASTNode
-		if tokens[0].Value == "hii" {
-			object = ast.ThisNode{}
-		} else {
-			object = ast.IdentifierNode{Value: tokens[0].Value}
-		}
-		return ast.MemberAssignmentNode{
-			Object: object,
-			Member: tokens[2].Value,
-			Value:  ParseExpression(tokens[4:]),
+// startsTopLevelDeclaration reports whether the current token begins something
+// allowed at the top level of a file: functions, classes and variable declarations
+func (p *Parser) startsTopLevelDeclaration() bool {
+	tok := p.peek()
+	if tok.Type == lexer.TokenKeyword {
+		switch tok.Value {
+		case "kazi", "darasa", "namba", "maneno", "boolean", "kamusi", "orodha":
+			return true
 		}
+		return false
 	}
+	// Class-typed variable (e.g., Mtu mtu1 = unda Mtu())
+	return tok.Type == lexer.TokenIdentifier && p.peekAt(1).Type == lexer.TokenIdentifier && p.peekAt(2).Value == "="
+}
 
-	// Handle array/dictionary assignment (must come before array access!)
-	if len(tokens) >= 6 && tokens[0].Type == lexer.TokenIdentifier && tokens[1].Value == "[" {
-		bracketEnd := -1
-		for i := 2; i < len(tokens); i++ {
-			if tokens[i].Value == "]" {
-				bracketEnd = i
-				break
-			}
-		}
-		if bracketEnd != -1 && bracketEnd+1 < len(tokens) && tokens[bracketEnd+1].Value == "=" {
-			return ast.ArrayAssignmentNode{
-				Array: ast.IdentifierNode{Value: tokens[0].Value},
-				Index: ParseExpression(tokens[2:bracketEnd]),
-				Value: ParseExpression(tokens[bracketEnd+2:]),
-			}
-		}
+// parseImport parses an import statement, optionally naming the module's
+// namespace (leta "module.swh" or leta "module.swh" kama jina)
+func (p *Parser) parseImport() (ast.ImportNode, bool) {
+	tok := p.peek()
+	p.pos++ // Skip "leta"
+	path, ok := p.expectModulePath("leta")
+	if !ok {
+		return ast.ImportNode{}, false
 	}
+	imp := ast.ImportNode{ModulePath: path.Value, Pos: posOf(tok)}
 
-	// Handle array/dictionary access
-	if len(tokens) >= 4 && tokens[0].Type == lexer.TokenIdentifier && tokens[1].Value == "[" {
-		for i := 2; i < len(tokens); i++ {
-			if tokens[i].Value == "]" {
-				return ast.ArrayAccessNode{
-					Array: ast.IdentifierNode{Value: tokens[0].Value},
-					Index: ParseExpression(tokens[2:i]),
-				}
-			}
+	// The alias must be on the same line; otherwise 'kama' starts an if statement
+	if p.isKeyword("kama") && p.peek().Line == path.Line {
+		p.pos++
+		if p.atEnd() || p.peek().Line != path.Line {
+			p.errorMissing("Jina la moduli linakosekana baada ya 'kama'", "missing module name after 'kama'")
+			return ast.ImportNode{}, false
 		}
-	}
-
-	// Handle assignment statements
-	if len(tokens) >= 3 && tokens[0].Type == lexer.TokenIdentifier && tokens[1].Value == "=" {
-		return ast.VariableDeclarationNode{
-			Name:  tokens[0].Value,
-			Value: ParseExpression(tokens[2:]),
+		alias, ok := p.expectName("jina la moduli baada ya 'kama'", "a module name after 'kama'")
+		if !ok {
+			return ast.ImportNode{}, false
 		}
+		imp.ImportName = alias.Value
 	}
+	return imp, true
+}
 
-	// Handle array declarations
-	if tokens[0].Value == "orodha" && len(tokens) >= 5 && tokens[3].Value == "=" {
-		arrayLiteral := ParseArrayLiteral(tokens[4:])
-		var elements []ast.ASTNode
-		if arrayNode, ok := arrayLiteral.(ast.ArrayNode); ok {
-			elements = arrayNode.Elements
-		}
-		return ast.ArrayDeclarationNode{
-			Name:     tokens[2].Value,
-			Type:     tokens[1].Value,
-			Elements: elements,
-		}
+// parseFromImport parses a selective import (kutoka "module.swh" leta a, b)
+func (p *Parser) parseFromImport() (ast.ImportNode, bool) {
+	tok := p.peek()
+	p.pos++ // Skip "kutoka"
+	path, ok := p.expectModulePath("kutoka")
+	if !ok {
+		return ast.ImportNode{}, false
 	}
-
-	// Handle method calls with dot notation (e.g., object.method(args))
-	// Must check before regular function calls
-	if len(tokens) >= 4 && tokens[1].Value == "." && tokens[3].Value == "(" {
-		var object ast.ASTNode
-		if tokens[0].Value == "hii" {
-			object = ast.ThisNode{}
-		} else {
-			object = ast.IdentifierNode{Value: tokens[0].Value}
-		}
-		return ast.MethodCallNode{
-			Object: object,
-			Method: tokens[2].Value,
-			Args:   ParseArguments(tokens[4:]),
-		}
+	if !p.isKeyword("leta") {
+		sw, en := describeToken(p.peek(), p.atEnd())
+		p.errorMissing("Nilitarajia 'leta' baada ya jina la moduli lakini nimepata "+sw,
+			"expected 'leta' after the module path but found "+en)
+		return ast.ImportNode{}, false
 	}
+	p.pos++
 
-	// Handle function calls (built-in and user-defined)
-	if tokens[0].Type == lexer.TokenIdentifier && len(tokens) > 1 && tokens[1].Value == "(" {
-		return ast.FunctionCallNode{
-			Name: tokens[0].Value,
-			Args: ParseArguments(tokens[2:]),
+	imp := ast.ImportNode{ModulePath: path.Value, Pos: posOf(tok)}
+	for {
+		item, ok := p.expectName("jina la kuleta", "a name to import")
+		if !ok {
+			return ast.ImportNode{}, false
 		}
-	}
-	
-	// Legacy: Handle specific built-in function calls
-	if (tokens[0].Value == "andika" || tokens[0].Value == "ongeza" || tokens[0].Value == "ondoa" || tokens[0].Value == "urefu_orodha" || tokens[0].Value == "pata" || tokens[0].Value == "soma" || tokens[0].Value == "andika_faili" || tokens[0].Value == "unda_faili" || tokens[0].Value == "faili_ipo" || tokens[0].Value == "ondoa_faili") && len(tokens) > 1 && tokens[1].Value == "(" {
-		return ast.FunctionCallNode{
-			Name: tokens[0].Value,
-			Args: ParseArguments(tokens[2:]),
+		imp.Items = append(imp.Items, item.Value)
+		if !p.isPunctuation(",") {
+			return imp, true
 		}
+		p.pos++
 	}
+}
 
-	// Handle identifiers (including module function calls like math.add)
-	if tokens[0].Type == lexer.TokenIdentifier {
-		return ast.IdentifierNode{Value: tokens[0].Value}
-	}
+// expectModulePath consumes the module path string after leta or kutoka
+func (p *Parser) expectModulePath(keyword string) (lexer.Token, bool) {
+	path := p.peek()
+	if p.atEnd() || path.Type != lexer.TokenString {
+		sw, en := describeToken(path, p.atEnd())
+		p.errorHere("Nilitarajia jina la moduli kama maneno baada ya '"+keyword+"' lakini nimepata "+sw,
+			"expected a module path string after '"+keyword+"' but found "+en)
+		return path, false
+	}
+	p.pos++
+	return path, true
+}
 
-	// Handle numbers
-	if tokens[0].Type == lexer.TokenNumber {
-		return ast.NumberNode{Value: tokens[0].Value}
+// Parse parses a single statement from the given tokens
+func Parse(tokens []lexer.Token) ast.ASTNode {
+	if len(tokens) == 0 {
+		return nil
 	}
-
-	return nil
+	return NewParser(tokens).parseStatement()
 }
 
-// ParseBlock parses a block of statements
+// ParseBlock parses a sequence of statements
 func ParseBlock(tokens []lexer.Token) []ast.ASTNode {
-	var statements []ast.ASTNode
-	i := 0
-
-	for i < len(tokens) {
-		if tokens[i].Value == "" {
-			i++
-			continue
-		}
-
-		// Parse control flow statements (if, while, for, try)
-		if tokens[i].Value == "kama" || tokens[i].Value == "wakati" || tokens[i].Value == "kwa" || tokens[i].Value == "jaribu" {
-			end := i + 1
-			braceCount := 0
-			foundFirstBrace := false
-
-			for end < len(tokens) {
-				if tokens[end].Value == "{" {
-					braceCount++
-					foundFirstBrace = true
-				} else if tokens[end].Value == "}" {
-					braceCount--
-					if braceCount == 0 && foundFirstBrace {
-						// Check for else/catch/finally
-						if end+1 < len(tokens) && (tokens[end+1].Value == "sivyo" || tokens[end+1].Value == "shika" || tokens[end+1].Value == "hatimaye") {
-							end++
-							continue
-						} else {
-							end++
-							break
-						}
-					}
-				}
-				end++
-			}
-
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
-			continue
-		}
-
-		// Parse simple statements (break, continue, throw)
-		if tokens[i].Value == "vunja" || tokens[i].Value == "endelea" {
-			stmt := Parse(tokens[i : i+1])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i++
-			continue
-		}
-
-		// Parse throw statements
-		if tokens[i].Value == "tupa" {
-			end := i + 1
-			for end < len(tokens) && tokens[end].Value != "namba" && tokens[end].Value != "maneno" && tokens[end].Value != "andika" && tokens[end].Value != "kama" && tokens[end].Value != "wakati" {
-				end++
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
-			continue
-		}
-
-		// Parse return statements
-		if tokens[i].Value == "rudisha" {
-			end := i + 1
-			for end < len(tokens) && tokens[end].Value != "namba" && tokens[end].Value != "maneno" && tokens[end].Value != "andika" && tokens[end].Value != "orodha" && tokens[end].Value != "rudisha" {
-				end++
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
-			continue
-		}
-
-		// Parse variable declarations (including kazi for lambda functions)
-		if (tokens[i].Value == "namba" || tokens[i].Value == "maneno" || tokens[i].Value == "boolean" || tokens[i].Value == "kazi") && i+3 < len(tokens) && tokens[i+2].Value == "=" {
-			end := i + 3
-			braceCount := 0
-			parenCount := 0
-			inLambda := false
-			justClosedParen := false
-			
-			// Check if this is a lambda assignment
-			if end < len(tokens) && tokens[end].Value == "lambda" {
-				inLambda = true
-			}
-			
-			for end < len(tokens) {
-				// Track parentheses and braces
-				if tokens[end].Value == "(" {
-					parenCount++
-					justClosedParen = false
-				} else if tokens[end].Value == ")" {
-					parenCount--
-					if parenCount == 0 {
-						justClosedParen = true
-					}
-				} else if tokens[end].Value == "{" {
-					braceCount++
-					justClosedParen = false
-				} else if tokens[end].Value == "}" {
-					braceCount--
-					justClosedParen = false
-					if braceCount == 0 && parenCount == 0 {
-						end++
-						break
-					}
-				} else {
-					// If we just closed a paren and see a type keyword, it might be a return type
-					if justClosedParen && inLambda && (tokens[end].Value == "namba" || tokens[end].Value == "maneno" || tokens[end].Value == "boolean") {
-						// This is a return type, continue
-						justClosedParen = false
-					} else {
-						justClosedParen = false
-						
-						// Only stop at keywords if we're not inside braces or parentheses
-						if braceCount == 0 && parenCount == 0 {
-							if tokens[end].Value == "namba" || tokens[end].Value == "maneno" || tokens[end].Value == "boolean" || tokens[end].Value == "kazi" || tokens[end].Value == "andika" || tokens[end].Value == "orodha" || tokens[end].Value == "kama" || tokens[end].Value == "wakati" || tokens[end].Value == "kwa" || tokens[end].Value == "vunja" || tokens[end].Value == "endelea" || tokens[end].Value == "rudisha" || tokens[end].Value == "tupa" {
-								break
-							}
-							// Stop at assignment statements (identifier followed by =)
-							if end+1 < len(tokens) && tokens[end].Type == lexer.TokenIdentifier && tokens[end+1].Value == "=" {
-								break
-							}
-						}
-					}
-				}
-				end++
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
-			continue
-		}
-
-		// Parse dictionary declarations
-		if tokens[i].Value == "kamusi" && i+3 < len(tokens) && tokens[i+2].Value == "=" {
-			end := i + 3
-			// Check if it's a dictionary literal or function call
-			if tokens[end].Value == "{" {
-				// Dictionary literal
-				braceCount := 0
-				for end < len(tokens) {
-					if tokens[end].Value == "{" {
-						braceCount++
-					} else if tokens[end].Value == "}" {
-						braceCount--
-						if braceCount == 0 {
-							end++
-							break
-						}
-					}
-					end++
-				}
-			} else {
-				// Function call or expression
-				for end < len(tokens) {
-					if tokens[end].Value == "namba" || tokens[end].Value == "maneno" || tokens[end].Value == "boolean" || tokens[end].Value == "kamusi" || tokens[end].Value == "andika" || tokens[end].Value == "orodha" || tokens[end].Value == "kama" || tokens[end].Value == "wakati" || tokens[end].Value == "kwa" || tokens[end].Value == "vunja" || tokens[end].Value == "endelea" || tokens[end].Value == "rudisha" || tokens[end].Value == "tupa" {
-						break
-					}
-					if end+1 < len(tokens) && tokens[end].Type == lexer.TokenIdentifier && tokens[end+1].Value == "=" {
-						break
-					}
-					end++
-				}
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
-			continue
-		}
+	p := NewParser(tokens)
+	return p.parseStatements()
+}
 
-		// Parse array declarations
-		if tokens[i].Value == "orodha" && i+4 < len(tokens) && tokens[i+3].Value == "=" {
-			end := i + 4
-			bracketCount := 0
-			for end < len(tokens) {
-				if tokens[end].Value == "[" {
-					bracketCount++
-				} else if tokens[end].Value == "]" {
-					bracketCount--
-					if bracketCount == 0 {
-						end++
-						break
-					}
-				}
-				end++
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
-			continue
-		}
+// parseStatements parses statements until the end of input or a closing brace,
+// recovering from errors at statement boundaries
+func (p *Parser) parseStatements() []ast.ASTNode {
+	var statements []ast.ASTNode
 
-		// Parse member assignment (e.g., obj.property = value or hii.property = value)
-		if i+4 < len(tokens) && (tokens[i].Type == lexer.TokenIdentifier || tokens[i].Value == "hii") && tokens[i+1].Value == "." && tokens[i+3].Value == "=" {
-			end := i + 4
-			for end < len(tokens) {
-				if tokens[end].Value == "namba" || tokens[end].Value == "maneno" || tokens[end].Value == "boolean" || tokens[end].Value == "kamusi" || tokens[end].Value == "andika" || tokens[end].Value == "orodha" || tokens[end].Value == "kama" || tokens[end].Value == "wakati" || tokens[end].Value == "kwa" || tokens[end].Value == "vunja" || tokens[end].Value == "endelea" || tokens[end].Value == "rudisha" || tokens[end].Value == "tupa" {
-					break
-				}
-				// Check for next member assignment (hii.property = value) or regular assignment (identifier = value)
-				if end+3 < len(tokens) && (tokens[end].Type == lexer.TokenIdentifier || tokens[end].Value == "hii") && tokens[end+1].Value == "." && tokens[end+3].Value == "=" {
-					break // Next member assignment found
-				}
-				if end+1 < len(tokens) && tokens[end].Type == lexer.TokenIdentifier && tokens[end+1].Value == "=" {
-					break // Next regular assignment found
-				}
-				end++
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
+	for !p.atEnd() && !p.isPunctuation("}") {
+		// Optional statement separator
+		if p.isPunctuation(";") {
+			p.pos++
 			continue
 		}
 
-		// Parse array/dictionary assignment (e.g., arr[0] = 5 or dict["key"] = value)
-		if i+3 < len(tokens) && tokens[i].Type == lexer.TokenIdentifier && tokens[i+1].Value == "[" {
-			bracketEnd := -1
-			for j := i + 2; j < len(tokens); j++ {
-				if tokens[j].Value == "]" {
-					bracketEnd = j
-					break
-				}
-			}
-			if bracketEnd != -1 && bracketEnd+1 < len(tokens) && tokens[bracketEnd+1].Value == "=" {
-				end := bracketEnd + 2
-				for end < len(tokens) {
-					if tokens[end].Value == "namba" || tokens[end].Value == "maneno" || tokens[end].Value == "boolean" || tokens[end].Value == "andika" || tokens[end].Value == "orodha" || tokens[end].Value == "kamusi" || tokens[end].Value == "kama" || tokens[end].Value == "wakati" || tokens[end].Value == "kwa" || tokens[end].Value == "vunja" || tokens[end].Value == "endelea" || tokens[end].Value == "rudisha" || tokens[end].Value == "tupa" {
-						break
-					}
-					if end+1 < len(tokens) && tokens[end].Type == lexer.TokenIdentifier && tokens[end+1].Value == "=" {
-						break
-					}
-					end++
-				}
-				stmt := Parse(tokens[i:end])
-				if stmt != nil {
-					statements = append(statements, stmt)
-				}
-				i = end
-				continue
-			}
-		}
-
-		// Parse assignment statements (e.g., i = i + 1)
-		if i+2 < len(tokens) && tokens[i].Type == lexer.TokenIdentifier && tokens[i+1].Value == "=" {
-			end := i + 2
-			for end < len(tokens) {
-				// Stop at keywords that start new statements
-				if tokens[end].Value == "namba" || tokens[end].Value == "maneno" || tokens[end].Value == "boolean" || tokens[end].Value == "andika" || tokens[end].Value == "orodha" || tokens[end].Value == "kama" || tokens[end].Value == "wakati" || tokens[end].Value == "kwa" || tokens[end].Value == "vunja" || tokens[end].Value == "endelea" || tokens[end].Value == "rudisha" || tokens[end].Value == "tupa" {
-					break
-				}
-				// Stop at another assignment statement (identifier followed by =)
-				if end+1 < len(tokens) && tokens[end].Type == lexer.TokenIdentifier && tokens[end+1].Value == "=" {
-					break
-				}
-				end++
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
+		start := p.pos
+		failures := p.failures
+		stmt := p.parseStatement()
+		if p.failures > failures {
+			p.synchronize(start)
 			continue
 		}
-
-		// Parse method calls (e.g., object.method(args))
-		if i+3 < len(tokens) && tokens[i].Type == lexer.TokenIdentifier && tokens[i+1].Value == "." && tokens[i+3].Value == "(" {
-			end := i + 4
-			parenCount := 1
-			for end < len(tokens) && parenCount > 0 {
-				if tokens[end].Value == "(" {
-					parenCount++
-				} else if tokens[end].Value == ")" {
-					parenCount--
-				}
-				end++
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
-			continue
+		if stmt != nil {
+			statements = append(statements, stmt)
 		}
-
-		// Parse function calls
-		if i+1 < len(tokens) && tokens[i+1].Value == "(" {
-			end := i + 2
-			parenCount := 1
-			for end < len(tokens) && parenCount > 0 {
-				if tokens[end].Value == "(" {
-					parenCount++
-				} else if tokens[end].Value == ")" {
-					parenCount--
-				}
-				end++
-			}
-			stmt := Parse(tokens[i:end])
-			if stmt != nil {
-				statements = append(statements, stmt)
-			}
-			i = end
-			continue
-		}
-
-		i++
 	}
 
 	return statements
 }
 
-// ParseExpression parses an expression
-func ParseExpression(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) == 0 {
+// parseBody parses a brace-delimited block of statements ({ ... })
+func (p *Parser) parseBody() []ast.ASTNode {
+	if !p.expect("{") {
 		return nil
 	}
+	open := p.tokens[p.pos-1]
+	inCondition := p.inCondition
+	p.inCondition = false
+	body := p.parseStatements()
+	p.inCondition = inCondition
+	if p.atEnd() {
+		p.errorAt(open, "Mabano '{' hayajafungwa", "unclosed '{'")
+		return body
+	}
+	p.pos++ // Skip "}"
+	return body
+}
 
-	// Handle lambda functions (lambda(params) { body })
-	if tokens[0].Value == "lambda" && len(tokens) >= 3 {
-		return ParseLambda(tokens)
-	}
-
-	// Handle class instantiation (unda ClassName(args))
-	if tokens[0].Value == "unda" && len(tokens) >= 3 {
-		return ParseNewInstance(tokens)
-	}
-
-	// Handle array literals
-	if len(tokens) >= 2 && tokens[0].Value == "[" {
-		return ParseArrayLiteral(tokens)
-	}
+// parseStatement parses one statement starting at the current token
+func (p *Parser) parseStatement() ast.ASTNode {
+	return p.parseStatementAllowing(false)
+}
 
-	// Handle function calls like ingiza("prompt")
-	if len(tokens) >= 3 && tokens[1].Value == "(" {
-		if tokens[0].Value == "ingiza" {
-			for i := 2; i < len(tokens); i++ {
-				if tokens[i].Value == ")" {
-					if i > 2 && tokens[2].Type == lexer.TokenString {
-						return ast.InputNode{Prompt: tokens[2].Value}
-					}
-					return ast.InputNode{}
-				}
-			}
-		} else {
-			return ast.FunctionCallNode{
-				Name: tokens[0].Value,
-				Args: ParseArguments(tokens[2:]),
-			}
+// parseStatementAllowing parses a statement; with expressions set, a bare
+// expression is accepted as a statement too
+func (p *Parser) parseStatementAllowing(expressions bool) ast.ASTNode {
+	tok := p.peek()
+
+	if tok.Type == lexer.TokenKeyword {
+		switch tok.Value {
+		case "kama":
+			// Handle conditional statements
+			return p.parseIfStatement()
+		case "wakati":
+			// Handle while loops
+			return p.parseWhileStatement()
+		case "kwa":
+			// Handle for loops
+			return p.parseForStatement()
+		case "chagua":
+			// Handle pattern matching
+			return p.parseMatchStatement()
+		case "jaribu":
+			// Handle try-catch statements
+			return p.parseTryStatement()
+		case "darasa":
+			// Handle class definitions
+			return p.parseClassDefinition()
+		case "vunja":
+			// Handle break statements
+			p.pos++
+			return ast.BreakNode{Pos: posOf(tok)}
+		case "endelea":
+			// Handle continue statements
+			p.pos++
+			return ast.ContinueNode{Pos: posOf(tok)}
+		case "rudisha":
+			// Handle return statements; the value must start on the same line
+			p.pos++
+			next := p.peek()
+			if p.atEnd() || next.Line != tok.Line || p.isPunctuation("}") || p.isPunctuation(";") {
+				return ast.ReturnNode{Value: nil, Pos: posOf(tok)}
+			}
+			value := p.parseExpression(0)
+			return ast.ReturnNode{Value: value, Pos: posOf(tok)}
+		case "tupa":
+			// Handle throw statements
+			p.pos++
+			message := p.parseExpression(0)
+			return ast.ThrowNode{Message: message, Pos: posOf(tok)}
+		case "kazi":
+			// Handle function definitions and function variables (kazi name = lambda() { ... })
+			if p.peekAt(2).Value == "=" {
+				return p.parseDeclaration()
+			}
+			return p.parseFunctionDefinition()
+		case "namba", "maneno", "boolean", "kamusi", "orodha":
+			// Handle variable declarations
+			return p.parseDeclaration()
+		case "leta", "kutoka", "moduli", "umma":
+			p.errorHere("'"+tok.Value+"' inaruhusiwa tu juu ya faili", "'"+tok.Value+"' is only allowed at the top of a file")
+			return nil
 		}
 	}
 
-	// Handle binary operations - need to find the operator position
-	// Skip over complex expressions like array access, member access, function calls
-	opIndex := -1
-	parenDepth := 0
-	bracketDepth := 0
-	
-	for i := 0; i < len(tokens); i++ {
-		if tokens[i].Value == "(" {
-			parenDepth++
-		} else if tokens[i].Value == ")" {
-			parenDepth--
-		} else if tokens[i].Value == "[" {
-			bracketDepth++
-		} else if tokens[i].Value == "]" {
-			bracketDepth--
-		} else if parenDepth == 0 && bracketDepth == 0 && tokens[i].Type == lexer.TokenOperator && tokens[i].Value != "=" {
-			opIndex = i
-			break
-		}
-	}
-	
-	if opIndex > 0 && opIndex < len(tokens)-1 {
-		return ast.BinaryOpNode{
-			Left:  ParseExpression(tokens[:opIndex]),
-			Op:    tokens[opIndex].Value,
-			Right: ParseExpression(tokens[opIndex+1:]),
-		}
+	// Handle class-typed variable declarations (e.g., Mtu mtu1 = unda Mtu("Amina"))
+	if tok.Type == lexer.TokenIdentifier && p.peekAt(1).Type == lexer.TokenIdentifier && p.peekAt(2).Value == "=" {
+		return p.parseDeclaration()
 	}
 
-	// Handle method calls with dot notation (e.g., object.method(args)) - MUST come before member access!
-	if len(tokens) >= 4 && tokens[1].Value == "." && tokens[3].Value == "(" {
-		var object ast.ASTNode
-		if tokens[0].Value == "hii" {
-			object = ast.ThisNode{}
-		} else {
-			object = ast.IdentifierNode{Value: tokens[0].Value}
-		}
-		return ast.MethodCallNode{
-			Object: object,
-			Method: tokens[2].Value,
-			Args:   ParseArguments(tokens[4:]),
-		}
+	return p.parseSimpleStatement(expressions)
+}
+
+// parseSimpleStatement parses an assignment or an expression used as a statement.
+// Unless expressions is set, the expression must have an effect (a call or unda).
+func (p *Parser) parseSimpleStatement(expressions bool) ast.ASTNode {
+	tok := p.peek()
+	target := p.parseExpression(0)
+	if target == nil {
+		return nil
 	}
 
-	// Handle member access with dot notation (e.g., hii.jina) - MUST come before 'hii' keyword check!
-	if len(tokens) >= 3 && tokens[1].Value == "." {
-		var object ast.ASTNode
-		if tokens[0].Value == "hii" {
-			object = ast.ThisNode{}
-		} else {
-			object = ast.IdentifierNode{Value: tokens[0].Value}
+	// Handle assignment statements (x = 1, arr[0] = 1, dict["k"] = 1, hii.jina = "Amina")
+	// and compound assignments (x += 1, arr[0] *= 2, hii.umri += 1)
+	if op, isAssignment := p.assignmentOperator(); isAssignment {
+		assignTok := p.peek()
+		p.pos++
+		value := p.parseExpression(0)
+		if value == nil {
+			return nil
 		}
-		return ast.MemberAccessNode{
-			Object: object,
-			Member: tokens[2].Value,
+		if assignment := assignTo(target, op, value); assignment != nil {
+			return assignment
 		}
+		p.errorAt(assignTok, "Upande wa kushoto wa '"+assignTok.Value+"' hauwezi kupewa thamani",
+			"the left side of '"+assignTok.Value+"' cannot be assigned to")
+		return nil
 	}
 
-	// Handle 'hii' keyword (this/self)
-	if tokens[0].Value == "hii" {
-		return ast.ThisNode{}
+	if expressions {
+		return target
 	}
 
-	// Handle boolean literals
-	if tokens[0].Value == "kweli" {
-		return ast.BooleanNode{Value: true}
-	}
-	if tokens[0].Value == "uwongo" {
-		return ast.BooleanNode{Value: false}
+	// Only expressions with an effect make sense as statements
+	switch target.(type) {
+	case ast.FunctionCallNode, ast.MethodCallNode, ast.CallNode, ast.NewInstanceNode:
+		return target
 	}
+	sw, en := describeToken(tok, false)
+	p.errorAt(tok, "Usemi unaoanza na "+sw+" si kauli kamili", "expression starting with "+en+" is not a complete statement")
+	return nil
+}
 
-	// Handle string literals
-	if tokens[0].Type == lexer.TokenString {
-		return ast.StringNode{Value: tokens[0].Value}
-	}
+// compoundAssignments maps each compound assignment operator to the binary
+// operator it applies (x += 1 is x = x + 1)
+var compoundAssignments = map[string]string{
+	"+=": "+", "-=": "-", "*=": "*", "/=": "/", "%=": "%", "**=": "**",
+}
 
-	// Handle numbers
-	if tokens[0].Type == lexer.TokenNumber {
-		return ast.NumberNode{Value: tokens[0].Value}
+// assignmentOperator reports whether the current token is = or a compound
+// assignment, and returns the binary operator a compound assignment applies
+func (p *Parser) assignmentOperator() (string, bool) {
+	tok := p.peek()
+	if tok.Type != lexer.TokenOperator {
+		return "", false
 	}
-
-
-
-	// Handle array/dictionary access (e.g., arr[0] or dict["key"])
-	if len(tokens) >= 4 && tokens[0].Type == lexer.TokenIdentifier && tokens[1].Value == "[" {
-		for i := 2; i < len(tokens); i++ {
-			if tokens[i].Value == "]" {
-				return ast.ArrayAccessNode{
-					Array: ast.IdentifierNode{Value: tokens[0].Value},
-					Index: ParseExpression(tokens[2:i]),
-				}
-			}
-		}
+	if tok.Value == "=" {
+		return "", true
 	}
+	op, isCompound := compoundAssignments[tok.Value]
+	return op, isCompound
+}
 
-	// Handle identifiers
-	if tokens[0].Type == lexer.TokenIdentifier {
-		return ast.IdentifierNode{Value: tokens[0].Value}
+// assignTo builds the assignment of value to target, combining it with the old
+// value through op for a compound assignment. It returns nil if target is not
+// a variable, element or member.
+func assignTo(target ast.ASTNode, op string, value ast.ASTNode) ast.ASTNode {
+	switch t := target.(type) {
+	case ast.IdentifierNode:
+		if op != "" {
+			// Reading a variable has no side effects, so x += 1 is simply x = x + 1
+			value = ast.BinaryOpNode{Left: t, Op: op, Right: value, Pos: t.Pos}
+		}
+		return ast.VariableDeclarationNode{Name: t.Value, Value: value, Pos: t.Pos}
+	case ast.MemberAccessNode:
+		return ast.MemberAssignmentNode{Object: t.Object, Member: t.Member, Op: op, Value: value, Pos: t.Pos}
+	case ast.ArrayAccessNode:
+		return ast.ArrayAssignmentNode{Array: t.Array, Index: t.Index, Op: op, Value: value, Pos: t.Pos}
 	}
-
 	return nil
 }
 
-// ParseArguments parses function arguments
-func ParseArguments(tokens []lexer.Token) []ast.ASTNode {
-	var args []ast.ASTNode
-	var currentArg []lexer.Token
+// parseDeclaration parses typed variable declarations (namba x = 10, orodha namba arr = [1, 2], Mtu m = unda Mtu())
+func (p *Parser) parseDeclaration() ast.ASTNode {
+	typeTok := p.peek()
+	p.pos++
 
-	for _, token := range tokens {
-		if token.Value == "," || token.Value == ")" {
-			if len(currentArg) > 0 {
-				if len(currentArg) == 1 && currentArg[0].Type == lexer.TokenString {
-					args = append(args, ast.StringNode{Value: currentArg[0].Value})
-				} else {
-					args = append(args, ParseExpression(currentArg))
-				}
-				currentArg = nil
-			}
-		} else {
-			currentArg = append(currentArg, token)
-		}
+	// Arrays carry an element type (orodha namba arr = [...])
+	elementType := ""
+	if typeTok.Value == "orodha" && p.peekAt(1).Value != "=" {
+		elementType = p.peek().Value
+		p.pos++
 	}
 
-	return args
-}
-
-// ParseFunctionDefinition parses function definitions
-func ParseFunctionDefinition(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) < 4 || tokens[0].Value != "kazi" {
+	nameTok, ok := p.expectName("jina la kigezo", "a variable name")
+	if !ok {
 		return nil
 	}
-
-	functionName := tokens[1].Value
-
-	// Parse parameters between ( and )
-	var parameters []ast.Parameter
-	if tokens[2].Value == "(" {
-		// Find closing parenthesis
-		parenEnd := -1
-		for i := 3; i < len(tokens); i++ {
-			if tokens[i].Value == ")" {
-				parenEnd = i
-				break
-			}
-		}
-
-		if parenEnd > 3 {
-			// Parse parameters
-			parameters = ParseParameters(tokens[3:parenEnd])
-		}
-	}
-
-	// Find the function body
-	braceStart := -1
-	braceEnd := -1
-	braceCount := 0
-
-	for i := 3; i < len(tokens); i++ {
-		if tokens[i].Value == "{" {
-			if braceCount == 0 {
-				braceStart = i + 1
-			}
-			braceCount++
-		} else if tokens[i].Value == "}" {
-			braceCount--
-			if braceCount == 0 {
-				braceEnd = i
-				break
-			}
-		}
-	}
-
-	if braceStart == -1 || braceEnd == -1 {
+	if !p.expect("=") {
 		return nil
 	}
-
-	// Parse the function body
-	body := ParseBlock(tokens[braceStart:braceEnd])
-
-	return ast.FunctionNode{
-		Name:       functionName,
-		Parameters: parameters,
-		Body:       body,
+	value := p.parseExpression(0)
+	if value == nil {
+		return nil
 	}
-}
 
-// ParseParameters parses function parameters
-func ParseParameters(tokens []lexer.Token) []ast.Parameter {
-	var parameters []ast.Parameter
-	i := 0
-
-	for i < len(tokens) {
-		// Expect: type name, type name, ...
-		if i+1 < len(tokens) {
-			paramType := tokens[i].Value
-			paramName := tokens[i+1].Value
-
-			parameters = append(parameters, ast.Parameter{
-				Name: paramName,
-				Type: paramType,
-			})
-
-			i += 2
-
-			// Skip comma if present
-			if i < len(tokens) && tokens[i].Value == "," {
-				i++
-			}
-		} else {
-			break
+	pos := posOf(typeTok)
+	switch typeTok.Value {
+	case "maneno":
+		return ast.StringVariableDeclarationNode{Name: nameTok.Value, Value: value, Pos: pos}
+	case "kamusi":
+		return ast.DictionaryDeclarationNode{Name: nameTok.Value, Value: value, Pos: pos}
+	case "orodha":
+		if arrayNode, isLiteral := value.(ast.ArrayNode); isLiteral {
+			return ast.ArrayDeclarationNode{Name: nameTok.Value, Type: elementType, Elements: arrayNode.Elements, Pos: pos}
 		}
+		return ast.ArrayDeclarationNode{Name: nameTok.Value, Type: elementType, Value: value, Pos: pos}
+	case "namba", "boolean", "kazi":
+		return ast.VariableDeclarationNode{Name: nameTok.Value, Type: typeTok.Value, Value: value, Pos: pos}
 	}
-
-	return parameters
+	return ast.ClassVariableDeclarationNode{ClassName: typeTok.Value, VarName: nameTok.Value, Value: value, Pos: pos}
 }
 
-// ParseArrayLiteral parses array literals
-func ParseArrayLiteral(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) < 2 || tokens[0].Value != "[" {
-		return nil
+// parseType parses a type name (namba, maneno, boolean, kamusi, kazi, orodha <type>, or a class name)
+func (p *Parser) parseType() (string, bool) {
+	tok := p.peek()
+	if p.atEnd() || (tok.Type != lexer.TokenKeyword && tok.Type != lexer.TokenIdentifier) {
+		sw, en := describeToken(tok, p.atEnd())
+		p.errorHere("Nilitarajia aina lakini nimepata "+sw, "expected a type but found "+en)
+		return "", false
 	}
-
-	closingBracket := -1
-	for i := 1; i < len(tokens); i++ {
-		if tokens[i].Value == "]" {
-			closingBracket = i
-			break
+	p.pos++
+	if tok.Value == "orodha" {
+		// Array types name their element type (orodha namba)
+		elem := p.peek()
+		if elem.Type == lexer.TokenKeyword && (elem.Value == "namba" || elem.Value == "maneno" || elem.Value == "boolean" || elem.Value == "kamusi") {
+			p.pos++
+			return "orodha " + elem.Value, true
 		}
 	}
+	return tok.Value, true
+}
 
-	if closingBracket == -1 {
-		return nil
+// parseParameters parses a parenthesised parameter list ((namba a, maneno b))
+func (p *Parser) parseParameters() ([]ast.Parameter, bool) {
+	if !p.expect("(") {
+		return nil, false
 	}
+	var parameters []ast.Parameter
+	for !p.isPunctuation(")") {
+		if p.atEnd() {
+			p.expect(")")
+			return parameters, false
+		}
+		// Expect: type name, type name, ...
+		paramType, ok := p.parseType()
+		if !ok {
+			return parameters, false
+		}
+		nameTok, ok := p.expectName("jina la kigezo", "a parameter name")
+		if !ok {
+			return parameters, false
+		}
+		parameters = append(parameters, ast.Parameter{
+			Name: nameTok.Value,
+			Type: paramType,
+			Pos:  posOf(nameTok),
+		})
+		if p.isPunctuation(",") {
+			p.pos++
+		} else if !p.isPunctuation(")") {
+			p.expect(")")
+			return parameters, false
+		}
+	}
+	p.pos++ // Skip ")"
+	return parameters, true
+}
 
-	var elements []ast.ASTNode
-	if closingBracket > 1 {
-		elements = ParseArrayElements(tokens[1:closingBracket])
+// parseReturnType parses an optional return type written between ')' and '{'
+func (p *Parser) parseReturnType() string {
+	if p.atEnd() || p.isPunctuation("{") {
+		return ""
 	}
-
-	return ast.ArrayNode{Elements: elements}
+	returnType, _ := p.parseType()
+	return returnType
 }
 
-// ParseArrayElements parses comma-separated array elements
-func ParseArrayElements(tokens []lexer.Token) []ast.ASTNode {
-	var elements []ast.ASTNode
-	var currentElement []lexer.Token
-
-	for _, token := range tokens {
-		if token.Value == "," {
-			if len(currentElement) > 0 {
-				element := ParseExpression(currentElement)
-				if element != nil {
-					elements = append(elements, element)
-				}
-				currentElement = nil
-			}
-		} else {
-			currentElement = append(currentElement, token)
-		}
-	}
+// parseFunctionDefinition parses function definitions (kazi jina(namba a) namba { ... })
+func (p *Parser) parseFunctionDefinition() ast.ASTNode {
+	kaziTok := p.peek()
+	p.pos++ // Skip "kazi"
 
-	if len(currentElement) > 0 {
-		element := ParseExpression(currentElement)
-		if element != nil {
-			elements = append(elements, element)
-		}
+	// The name may be a reserved word such as "unda" (class constructors)
+	nameTok := p.peek()
+	if p.atEnd() || (nameTok.Type != lexer.TokenIdentifier && nameTok.Type != lexer.TokenKeyword) {
+		sw, en := describeToken(nameTok, p.atEnd())
+		p.errorHere("Nilitarajia jina la kazi lakini nimepata "+sw, "expected a function name but found "+en)
+		return nil
 	}
+	p.pos++
 
-	return elements
-}
-
-// ParseIfStatement parses conditional statements (kama ... { ... } sivyo { ... })
-func ParseIfStatement(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) < 4 || tokens[0].Value != "kama" {
+	parameters, ok := p.parseParameters()
+	if !ok {
 		return nil
 	}
+	returnType := p.parseReturnType()
+	body := p.parseBody()
 
-	// Find the condition (between "kama" and "{")
-	conditionStart := 1
-	conditionEnd := -1
-	for i := conditionStart; i < len(tokens); i++ {
-		if tokens[i].Value == "{" {
-			conditionEnd = i
-			break
-		}
+	return ast.FunctionNode{
+		Name:       nameTok.Value,
+		Parameters: parameters,
+		ReturnType: returnType,
+		Body:       body,
+		Pos:        posOf(kaziTok),
 	}
+}
 
-	if conditionEnd == -1 {
+// parseCondition parses the condition of kama/wakati, which runs up to the opening brace
+func (p *Parser) parseCondition() ast.ASTNode {
+	if p.isPunctuation("{") {
+		p.errorHere("Sharti linakosekana kabla ya '{'", "missing condition before '{'")
 		return nil
 	}
+	p.inCondition = true
+	condition := p.parseExpression(0)
+	p.inCondition = false
+	return condition
+}
 
-	// Parse the condition
-	condition := ParseExpression(tokens[conditionStart:conditionEnd])
-
-	// Find the then body (between first "{" and "}")
-	thenStart := conditionEnd + 1
-	thenEnd := -1
-	braceCount := 1
-	for i := thenStart; i < len(tokens); i++ {
-		if tokens[i].Value == "{" {
-			braceCount++
-		} else if tokens[i].Value == "}" {
-			braceCount--
-			if braceCount == 0 {
-				thenEnd = i
-				break
-			}
-		}
-	}
+// parseIfStatement parses conditional statements (kama ... { ... } sivyo kama ... { ... } sivyo { ... })
+func (p *Parser) parseIfStatement() ast.ASTNode {
+	kamaTok := p.peek()
+	p.pos++ // Skip "kama"
 
-	if thenEnd == -1 {
+	condition := p.parseCondition()
+	if condition == nil {
 		return nil
 	}
+	thenBody := p.parseBody()
 
-	// Parse the then body
-	thenBody := ParseBlock(tokens[thenStart:thenEnd])
-
-	// Check for else clause (sivyo)
+	// Check for else clause (sivyo), including else-if chains (sivyo kama)
 	var elseBody []ast.ASTNode
-	if thenEnd+1 < len(tokens) && tokens[thenEnd+1].Value == "sivyo" {
-		// Find the else body
-		elseStart := -1
-		elseEnd := -1
-
-		// Look for opening brace after "sivyo"
-		for i := thenEnd + 2; i < len(tokens); i++ {
-			if tokens[i].Value == "{" {
-				elseStart = i + 1
-				break
-			}
-		}
-
-		if elseStart != -1 {
-			// Find closing brace for else body
-			braceCount = 1
-			for i := elseStart; i < len(tokens); i++ {
-				if tokens[i].Value == "{" {
-					braceCount++
-				} else if tokens[i].Value == "}" {
-					braceCount--
-					if braceCount == 0 {
-						elseEnd = i
-						break
-					}
-				}
-			}
-
-			if elseEnd != -1 {
-				elseBody = ParseBlock(tokens[elseStart:elseEnd])
+	if p.isKeyword("sivyo") {
+		p.pos++
+		if p.isKeyword("kama") {
+			if elseIf := p.parseIfStatement(); elseIf != nil {
+				elseBody = []ast.ASTNode{elseIf}
 			}
+		} else {
+			elseBody = p.parseBody()
 		}
 	}
 
@@ -1100,264 +693,189 @@ func ParseIfStatement(tokens []lexer.Token) ast.ASTNode {
 		Condition: condition,
 		ThenBody:  thenBody,
 		ElseBody:  elseBody,
+		Pos:       posOf(kamaTok),
 	}
 }
 
-// ParseWhileStatement parses while loops (wakati condition { ... })
-func ParseWhileStatement(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) < 4 || tokens[0].Value != "wakati" {
+// parseWhileStatement parses while loops (wakati condition { ... })
+func (p *Parser) parseWhileStatement() ast.ASTNode {
+	wakatiTok := p.peek()
+	p.pos++ // Skip "wakati"
+
+	condition := p.parseCondition()
+	if condition == nil {
 		return nil
 	}
+	body := p.parseBody()
 
-	// Find the condition (between "wakati" and "{")
-	conditionStart := 1
-	conditionEnd := -1
-	for i := conditionStart; i < len(tokens); i++ {
-		if tokens[i].Value == "{" {
-			conditionEnd = i
-			break
-		}
+	return ast.WhileNode{
+		Condition: condition,
+		Body:      body,
+		Pos:       posOf(wakatiTok),
 	}
+}
 
-	if conditionEnd == -1 {
+// parseForClause parses the init or update part of a for loop: a declaration, an assignment or an expression
+func (p *Parser) parseForClause() ast.ASTNode {
+	tok := p.peek()
+	if tok.Type == lexer.TokenKeyword && (tok.Value == "namba" || tok.Value == "maneno" || tok.Value == "boolean") {
+		return p.parseDeclaration()
+	}
+	target := p.parseExpression(0)
+	if target == nil {
 		return nil
 	}
-
-	// Parse the condition
-	condition := ParseExpression(tokens[conditionStart:conditionEnd])
-
-	// Find the body (between "{" and "}")
-	bodyStart := conditionEnd + 1
-	bodyEnd := -1
-	braceCount := 1
-	for i := bodyStart; i < len(tokens); i++ {
-		if tokens[i].Value == "{" {
-			braceCount++
-		} else if tokens[i].Value == "}" {
-			braceCount--
-			if braceCount == 0 {
-				bodyEnd = i
-				break
-			}
-		}
+	op, isAssignment := p.assignmentOperator()
+	if !isAssignment {
+		return target
 	}
-
-	if bodyEnd == -1 {
+	assignTok := p.peek()
+	p.pos++
+	value := p.parseExpression(0)
+	if value == nil {
 		return nil
 	}
-
-	// Parse the body
-	body := ParseBlock(tokens[bodyStart:bodyEnd])
-
-	return ast.WhileNode{
-		Condition: condition,
-		Body:      body,
+	if assignment := assignTo(target, op, value); assignment != nil {
+		return assignment
 	}
+	p.errorAt(tok, "Upande wa kushoto wa '"+assignTok.Value+"' hauwezi kupewa thamani",
+		"the left side of '"+assignTok.Value+"' cannot be assigned to")
+	return nil
 }
 
-// ParseForStatement parses for loops (kwa init; condition; update { ... })
-func ParseForStatement(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) < 4 || tokens[0].Value != "kwa" {
-		return nil
+// parseForStatement parses for loops (kwa init; condition; update { ... } or kwa condition { ... })
+func (p *Parser) parseForStatement() ast.ASTNode {
+	kwaTok := p.peek()
+	p.pos++ // Skip "kwa"
+	if p.isForEach() {
+		return p.parseForEach(kwaTok)
 	}
 
-	// Find the parts of the for loop (init; condition; update)
-	var initEnd, conditionEnd, updateEnd int = -1, -1, -1
-	semicolonCount := 0
+	// The loop header runs up to the opening brace of the body
+	p.inCondition = true
+	defer func() { p.inCondition = false }()
 
-	for i := 1; i < len(tokens); i++ {
-		if tokens[i].Value == ";" {
-			semicolonCount++
-			if semicolonCount == 1 {
-				initEnd = i
-			} else if semicolonCount == 2 {
-				conditionEnd = i
-			}
-		} else if tokens[i].Value == "{" {
-			updateEnd = i
-			break
-		}
+	first := p.parseForClause()
+	if first == nil {
+		return nil
 	}
 
-	// If we don't have semicolons, treat it as a simple for loop with just condition
-	if semicolonCount == 0 {
-		conditionStart := 1
-		conditionEnd := -1
-		for i := conditionStart; i < len(tokens); i++ {
-			if tokens[i].Value == "{" {
-				conditionEnd = i
-				break
-			}
-		}
-
-		if conditionEnd == -1 {
-			return nil
-		}
-
-		condition := ParseExpression(tokens[conditionStart:conditionEnd])
-
-		// Find the body
-		bodyStart := conditionEnd + 1
-		bodyEnd := -1
-		braceCount := 1
-		for i := bodyStart; i < len(tokens); i++ {
-			if tokens[i].Value == "{" {
-				braceCount++
-			} else if tokens[i].Value == "}" {
-				braceCount--
-				if braceCount == 0 {
-					bodyEnd = i
-					break
-				}
-			}
-		}
-
-		if bodyEnd == -1 {
-			return nil
-		}
-
-		body := ParseBlock(tokens[bodyStart:bodyEnd])
-
+	// Simple for loop with just a condition
+	if !p.isPunctuation(";") {
+		body := p.parseBody()
 		return ast.ForNode{
 			Init:      nil,
-			Condition: condition,
+			Condition: first,
 			Update:    nil,
 			Body:      body,
+			Pos:       posOf(kwaTok),
 		}
 	}
 
 	// Full for loop with init; condition; update
-	if initEnd == -1 || conditionEnd == -1 || updateEnd == -1 {
+	p.pos++ // Skip ";"
+	var condition, update ast.ASTNode
+	if !p.isPunctuation(";") {
+		condition = p.parseExpression(0)
+	}
+	if !p.expect(";") {
 		return nil
 	}
-
-	// Parse init, condition, and update
-	var init, condition, update ast.ASTNode
-
-	if initEnd > 1 {
-		init = ParseExpression(tokens[1:initEnd])
+	if !p.isPunctuation("{") {
+		update = p.parseForClause()
 	}
+	body := p.parseBody()
 
-	if conditionEnd > initEnd+1 {
-		condition = ParseExpression(tokens[initEnd+1 : conditionEnd])
+	return ast.ForNode{
+		Init:      first,
+		Condition: condition,
+		Update:    update,
+		Body:      body,
+		Pos:       posOf(kwaTok),
 	}
+}
 
-	if updateEnd > conditionEnd+1 {
-		update = ParseExpression(tokens[conditionEnd+1 : updateEnd])
+// isForEach reports whether the loop after kwa is a for-each loop. kila and katika
+// are only special here, so programs can still use them as names.
+func (p *Parser) isForEach() bool {
+	if tok := p.peek(); tok.Type != lexer.TokenIdentifier || tok.Value != "kila" {
+		return false
 	}
+	next := p.peekAt(2)
+	return p.peekAt(1).Type == lexer.TokenIdentifier && (next.Value == "katika" || next.Value == ",")
+}
 
-	// Find the body
-	bodyStart := updateEnd + 1
-	bodyEnd := -1
-	braceCount := 1
-	for i := bodyStart; i < len(tokens); i++ {
-		if tokens[i].Value == "{" {
-			braceCount++
-		} else if tokens[i].Value == "}" {
-			braceCount--
-			if braceCount == 0 {
-				bodyEnd = i
-				break
-			}
+// parseForEach parses for-each loops (kwa kila x katika orodha { ... } or
+// kwa kila ufunguo, thamani katika kamusi { ... })
+func (p *Parser) parseForEach(kwaTok lexer.Token) ast.ASTNode {
+	p.pos++ // Skip "kila"
+	first, ok := p.expectName("jina la kigezo cha mzunguko", "a loop variable name")
+	if !ok {
+		return nil
+	}
+	loop := ast.ForEachNode{Value: first.Value, Pos: posOf(kwaTok)}
+	if p.isPunctuation(",") {
+		p.pos++
+		second, ok := p.expectName("jina la kigezo cha pili cha mzunguko", "a second loop variable name")
+		if !ok {
+			return nil
 		}
+		loop.Key, loop.Value = first.Value, second.Value
 	}
-
-	if bodyEnd == -1 {
+	if !p.expect("katika") {
 		return nil
 	}
-
-	body := ParseBlock(tokens[bodyStart:bodyEnd])
-
-	return ast.ForNode{
-		Init:      init,
-		Condition: condition,
-		Update:    update,
-		Body:      body,
+	if p.isPunctuation("{") {
+		p.errorHere("Kinachopitiwa kinakosekana kabla ya '{'", "missing what to loop over before '{'")
+		return nil
 	}
-}
-
-// ParseTryStatement parses try-catch statements (jaribu { ... } shika (var) { ... } hatimaye { ... })
-func ParseTryStatement(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) < 4 || tokens[0].Value != "jaribu" {
+	// Like a condition, the collection runs up to the opening brace of the body
+	p.inCondition = true
+	loop.Iterable = p.parseExpression(0)
+	p.inCondition = false
+	if loop.Iterable == nil {
 		return nil
 	}
+	loop.Body = p.parseBody()
+	return loop
+}
+
+// parseTryStatement parses try-catch statements (jaribu { ... } shika (var) { ... } hatimaye { ... })
+func (p *Parser) parseTryStatement() ast.ASTNode {
+	jaribuTok := p.peek()
+	p.pos++ // Skip "jaribu"
 
-	var tryBody []ast.ASTNode
 	var catchVar string
 	var catchBody []ast.ASTNode
 	var finallyBody []ast.ASTNode
 
-	i := 1
 	// Parse try block
-	if i < len(tokens) && tokens[i].Value == "{" {
-		i++ // Skip opening brace
-		tryStart := i
-		braceCount := 1
-		for i < len(tokens) && braceCount > 0 {
-			if tokens[i].Value == "{" {
-				braceCount++
-			} else if tokens[i].Value == "}" {
-				braceCount--
-			}
-			i++
-		}
-		tryEnd := i - 1
-		tryBody = ParseBlock(tokens[tryStart:tryEnd])
-	}
+	tryBody := p.parseBody()
 
 	// Parse catch block if present
-	if i < len(tokens) && tokens[i].Value == "shika" {
-		i++ // Skip "shika"
+	if p.isKeyword("shika") {
+		p.pos++ // Skip "shika"
 
 		// Parse catch variable
-		if i < len(tokens) && tokens[i].Value == "(" {
-			i++ // Skip "("
-			if i < len(tokens) && tokens[i].Type == lexer.TokenIdentifier {
-				catchVar = tokens[i].Value
-				i++
+		if p.isPunctuation("(") {
+			p.pos++
+			nameTok, ok := p.expectName("jina la kigezo cha hitilafu", "an error variable name")
+			if !ok {
+				return nil
 			}
-			if i < len(tokens) && tokens[i].Value == ")" {
-				i++ // Skip ")"
+			catchVar = nameTok.Value
+			if !p.expect(")") {
+				return nil
 			}
 		}
 
-		// Parse catch body
-		if i < len(tokens) && tokens[i].Value == "{" {
-			i++ // Skip opening brace
-			catchStart := i
-			braceCount := 1
-			for i < len(tokens) && braceCount > 0 {
-				if tokens[i].Value == "{" {
-					braceCount++
-				} else if tokens[i].Value == "}" {
-					braceCount--
-				}
-				i++
-			}
-			catchEnd := i - 1
-			catchBody = ParseBlock(tokens[catchStart:catchEnd])
-		}
+		catchBody = p.parseBody()
 	}
 
 	// Parse finally block if present
-	if i < len(tokens) && tokens[i].Value == "hatimaye" {
-		i++ // Skip "hatimaye"
-
-		if i < len(tokens) && tokens[i].Value == "{" {
-			i++ // Skip opening brace
-			finallyStart := i
-			braceCount := 1
-			for i < len(tokens) && braceCount > 0 {
-				if tokens[i].Value == "{" {
-					braceCount++
-				} else if tokens[i].Value == "}" {
-					braceCount--
-				}
-				i++
-			}
-			finallyEnd := i - 1
-			finallyBody = ParseBlock(tokens[finallyStart:finallyEnd])
-		}
+	if p.isKeyword("hatimaye") {
+		p.pos++ // Skip "hatimaye"
+		finallyBody = p.parseBody()
 	}
 
 	return ast.TryNode{
@@ -1365,207 +883,53 @@ func ParseTryStatement(tokens []lexer.Token) ast.ASTNode {
 		CatchVar:    catchVar,
 		CatchBody:   catchBody,
 		FinallyBody: finallyBody,
+		Pos:         posOf(jaribuTok),
 	}
 }
 
-// ParseDictionaryLiteral parses dictionary literals like {key: value, key2: value2}
-func ParseDictionaryLiteral(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) == 0 {
-		return nil
-	}
-	
-	// Handle empty dictionary {}
-	if len(tokens) == 2 && tokens[0].Value == "{" && tokens[1].Value == "}" {
-		return ast.DictionaryNode{Pairs: []ast.DictionaryPair{}}
-	}
-	
-	if tokens[0].Value != "{" {
-		return nil
-	}
-
-	closingBrace := -1
-	braceCount := 1
-	for i := 1; i < len(tokens); i++ {
-		if tokens[i].Value == "{" {
-			braceCount++
-		} else if tokens[i].Value == "}" {
-			braceCount--
-			if braceCount == 0 {
-				closingBrace = i
-				break
-			}
-		}
-	}
-
-	if closingBrace == -1 {
-		return nil
-	}
-
-	var pairs []ast.DictionaryPair
-	if closingBrace > 1 {
-		pairs = ParseDictionaryPairs(tokens[1:closingBrace])
-	}
-
-	return ast.DictionaryNode{Pairs: pairs}
-}
-
-// ParseDictionaryPairs parses comma-separated key:value pairs
-func ParseDictionaryPairs(tokens []lexer.Token) []ast.DictionaryPair {
-	var pairs []ast.DictionaryPair
-	var currentPair []lexer.Token
-
-	for _, token := range tokens {
-		if token.Value == "," {
-			if len(currentPair) > 0 {
-				pair := ParseDictionaryPair(currentPair)
-				if pair != nil {
-					pairs = append(pairs, *pair)
-				}
-				currentPair = nil
-			}
-		} else {
-			currentPair = append(currentPair, token)
-		}
-	}
-
-	if len(currentPair) > 0 {
-		pair := ParseDictionaryPair(currentPair)
-		if pair != nil {
-			pairs = append(pairs, *pair)
-		}
-	}
-
-	return pairs
-}
+// parseClassDefinition parses class definitions (darasa ClassName { ... } or darasa Child : Parent { ... })
+func (p *Parser) parseClassDefinition() ast.ASTNode {
+	darasaTok := p.peek()
+	p.pos++ // Skip "darasa"
 
-// ParseDictionaryPair parses a single key:value pair
-func ParseDictionaryPair(tokens []lexer.Token) *ast.DictionaryPair {
-	// Find the colon separator
-	colonIndex := -1
-	for i, token := range tokens {
-		if token.Value == ":" {
-			colonIndex = i
-			break
-		}
-	}
-
-	if colonIndex == -1 || colonIndex == 0 || colonIndex == len(tokens)-1 {
-		return nil
-	}
-
-	// Parse key - if it's a single string token, treat it as a string literal
-	var key ast.ASTNode
-	if colonIndex == 1 && tokens[0].Type == lexer.TokenString {
-		// String literal key
-		key = ast.StringNode{Value: tokens[0].Value}
-	} else {
-		// Expression key
-		key = ParseExpression(tokens[:colonIndex])
-	}
-	
-	value := ParseExpression(tokens[colonIndex+1:])
-
-	if key == nil || value == nil {
+	nameTok, ok := p.expectName("jina la darasa", "a class name")
+	if !ok {
 		return nil
 	}
 
-	return &ast.DictionaryPair{
-		Key:   key,
-		Value: value,
-	}
-}
-
-
-// ParseClassDefinition parses class definitions (darasa ClassName { ... } or darasa Child : Parent { ... })
-func ParseClassDefinition(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) < 3 || tokens[0].Value != "darasa" {
-		return nil
-	}
-
-	className := tokens[1].Value
-	parentClass := "" // For inheritance
-
 	// Check for inheritance syntax: darasa Child : Parent
-	startIndex := 2
-	if len(tokens) > 3 && tokens[2].Value == ":" {
-		parentClass = tokens[3].Value
-		startIndex = 4
-	}
-
-	// Find the class body
-	braceStart := -1
-	braceEnd := -1
-	braceCount := 0
-
-	for i := startIndex; i < len(tokens); i++ {
-		if tokens[i].Value == "{" {
-			if braceCount == 0 {
-				braceStart = i + 1
-			}
-			braceCount++
-		} else if tokens[i].Value == "}" {
-			braceCount--
-			if braceCount == 0 {
-				braceEnd = i
-				break
-			}
+	parentClass := ""
+	if p.isPunctuation(":") {
+		p.pos++
+		parentTok, ok := p.expectName("jina la darasa mzazi", "a parent class name")
+		if !ok {
+			return nil
 		}
+		parentClass = parentTok.Value
 	}
 
-	if braceStart == -1 || braceEnd == -1 {
+	if !p.expect("{") {
 		return nil
 	}
+	open := p.tokens[p.pos-1]
 
 	// Parse class body (properties and methods)
 	var properties []ast.PropertyNode
 	var methods []ast.FunctionNode
 	var constructor *ast.FunctionNode
 
-	bodyTokens := tokens[braceStart:braceEnd]
-	i := 0
-
-	for i < len(bodyTokens) {
-		if bodyTokens[i].Value == "" {
-			i++
-			continue
-		}
-
-		// Parse property declarations (type name)
-		if (bodyTokens[i].Value == "namba" || bodyTokens[i].Value == "maneno" || bodyTokens[i].Value == "boolean" || bodyTokens[i].Value == "kamusi" || bodyTokens[i].Value == "orodha") && i+1 < len(bodyTokens) {
-			propType := bodyTokens[i].Value
-			propName := bodyTokens[i+1].Value
-			properties = append(properties, ast.PropertyNode{
-				Name: propName,
-				Type: propType,
-			})
-			i += 2
-			continue
+	for !p.isPunctuation("}") {
+		if p.atEnd() {
+			p.errorAt(open, "Mabano '{' ya darasa hayajafungwa", "unclosed '{' of class body")
+			break
 		}
+		start := p.pos
+		failures := p.failures
+		tok := p.peek()
 
-		// Parse method definitions
-		if bodyTokens[i].Value == "kazi" {
-			// Find the end of this method
-			end := i + 1
-			methodBraceCount := 0
-			foundFirstBrace := false
-
-			for end < len(bodyTokens) {
-				if bodyTokens[end].Value == "{" {
-					methodBraceCount++
-					foundFirstBrace = true
-				} else if bodyTokens[end].Value == "}" {
-					methodBraceCount--
-					if methodBraceCount == 0 && foundFirstBrace {
-						end++
-						break
-					}
-				}
-				end++
-			}
-
-			// Parse this method
-			method := ParseFunctionDefinition(bodyTokens[i:end])
-			if funcNode, ok := method.(ast.FunctionNode); ok {
+		if tok.Type == lexer.TokenKeyword && tok.Value == "kazi" {
+			// Parse method definitions
+			if funcNode, ok := p.parseFunctionDefinition().(ast.FunctionNode); ok {
 				// Check if it's a constructor (named "unda")
 				if funcNode.Name == "unda" {
 					constructor = &funcNode
@@ -1573,121 +937,59 @@ func ParseClassDefinition(tokens []lexer.Token) ast.ASTNode {
 					methods = append(methods, funcNode)
 				}
 			}
-			i = end
+		} else if tok.Type == lexer.TokenKeyword || tok.Type == lexer.TokenIdentifier {
+			// Parse property declarations (type name [= default])
+			propType, ok := p.parseType()
+			var nameTok lexer.Token
+			if ok {
+				nameTok, ok = p.expectName("jina la sifa", "a property name")
+			}
+			if ok {
+				property := ast.PropertyNode{Name: nameTok.Value, Type: propType, Pos: posOf(nameTok)}
+				if p.isOperator("=") {
+					p.pos++
+					property.Value = p.parseExpression(0)
+				}
+				properties = append(properties, property)
+			}
 		} else {
-			i++
+			p.unexpected()
+		}
+
+		if p.failures > failures {
+			p.synchronize(start)
 		}
 	}
+	if p.isPunctuation("}") {
+		p.pos++
+	}
 
 	return ast.ClassNode{
-		Name:        className,
+		Name:        nameTok.Value,
 		Parent:      parentClass,
 		Properties:  properties,
 		Methods:     methods,
 		Constructor: constructor,
+		Pos:         posOf(darasaTok),
 	}
 }
 
+// parseLambda parses lambda/anonymous functions (lambda(params) [type] { body })
+func (p *Parser) parseLambda() ast.ASTNode {
+	lambdaTok := p.peek()
+	p.pos++ // Skip "lambda"
 
-// ParseNewInstance parses class instantiation (unda ClassName(args))
-func ParseNewInstance(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) < 3 || tokens[0].Value != "unda" {
+	parameters, ok := p.parseParameters()
+	if !ok {
 		return nil
 	}
-
-	className := tokens[1].Value
-
-	// Find arguments
-	if tokens[2].Value == "(" {
-		// Find closing parenthesis
-		parenEnd := -1
-		for i := 3; i < len(tokens); i++ {
-			if tokens[i].Value == ")" {
-				parenEnd = i
-				break
-			}
-		}
-
-		var args []ast.ASTNode
-		if parenEnd > 3 {
-			args = ParseArguments(tokens[3 : parenEnd+1]) // Include closing paren
-		}
-
-		return ast.NewInstanceNode{
-			ClassName: className,
-			Args:      args,
-		}
-	}
-
-	return nil
-}
-
-// ParseLambda parses lambda/anonymous functions (lambda(params) { body })
-func ParseLambda(tokens []lexer.Token) ast.ASTNode {
-	if len(tokens) < 3 || tokens[0].Value != "lambda" {
-		return nil
-	}
-
-	// Find parameters between ( and )
-	var parameters []ast.Parameter
-	var returnType string
-	braceStart := -1
-
-	if tokens[1].Value == "(" {
-		// Find closing parenthesis
-		parenEnd := -1
-		for i := 2; i < len(tokens); i++ {
-			if tokens[i].Value == ")" {
-				parenEnd = i
-				break
-			}
-		}
-
-		if parenEnd > 2 {
-			// Parse parameters
-			parameters = ParseParameters(tokens[2:parenEnd])
-		}
-
-		// Check for return type after )
-		if parenEnd+1 < len(tokens) && tokens[parenEnd+1].Value != "{" {
-			returnType = tokens[parenEnd+1].Value
-			braceStart = parenEnd + 2
-		} else {
-			braceStart = parenEnd + 1
-		}
-	}
-
-	// Find the lambda body
-	if braceStart >= len(tokens) || tokens[braceStart].Value != "{" {
-		return nil
-	}
-
-	braceEnd := -1
-	braceCount := 1 // Start with 1 since we're at the opening brace
-	bodyStart := braceStart + 1 // Body starts after the opening brace
-
-	for i := braceStart + 1; i < len(tokens); i++ {
-		if tokens[i].Value == "{" {
-			braceCount++
-		} else if tokens[i].Value == "}" {
-			braceCount--
-			if braceCount == 0 {
-				braceEnd = i
-				break
-			}
-		}
-	}
-
-	if braceEnd == -1 {
-		return nil
-	}
-
-	// Parse the lambda body
-	body := ParseBlock(tokens[bodyStart:braceEnd])
+	returnType := p.parseReturnType()
+	body := p.parseBody()
 
 	return ast.LambdaNode{
 		Parameters: parameters,
 		ReturnType: returnType,
 		Body:       body,
+		Pos:        posOf(lambdaTok),
 	}
 }